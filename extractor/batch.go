@@ -0,0 +1,101 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchResult is the outcome of extracting one URL of a batch. Exactly
+// one of Result and Err is set.
+type BatchResult struct {
+	URL    string
+	Result *URLResult
+	Err    error
+}
+
+// AllURLs returns the distinct URLs in text, in the order they appear.
+func AllURLs(text string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range linkPattern.FindAllString(text, -1) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// ExtractURLs extracts every URL concurrently with at most workers
+// fetches in flight. Each URL gets its own timeout; a URL that runs
+// over it is reported as failed. Results are in the same order as urls.
+// Canceling ctx fails every URL not yet done.
+func ExtractURLs(ctx context.Context, urls []string, workers int, timeout time.Duration) []BatchResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]BatchResult, len(urls))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(urls); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = extractBatchItem(ctx, urls[i], timeout)
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// extractBatchItem extracts one URL of a batch. A panic in an extractor,
+// such as a parser choking on a malformed linked file, is reported as
+// that URL's error rather than taking down the process.
+func extractBatchItem(ctx context.Context, url string, timeout time.Duration) (res BatchResult) {
+	urlCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			res = BatchResult{URL: url, Err: fmt.Errorf("extraction failed: %v", r)}
+		}
+	}()
+
+	result, err := ExtractURL(urlCtx, url)
+	if err != nil && errors.Is(urlCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return BatchResult{URL: url, Result: result, Err: err}
+}
+
+// MergeDocuments combines several documents into one, each becoming a
+// top-level chapter headed by its own title.
+func MergeDocuments(title string, docs []*Document) *Document {
+	var b docBuilder
+	for _, doc := range docs {
+		b.breakSection()
+		if doc.Title != "" {
+			b.heading(1, doc.Title)
+		}
+		for _, s := range doc.Sections {
+			// Keep nested headings below the new chapter heading.
+			if s.Heading != "" {
+				b.heading(min(s.Level+1, maxSectionLevel), s.Heading)
+			}
+			for _, p := range s.Paragraphs {
+				b.paragraph(p)
+			}
+		}
+	}
+	return b.document(title)
+}
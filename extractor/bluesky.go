@@ -0,0 +1,221 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// blueskyAPI is the public Bluesky AppView, which serves posts without
+// an account.
+var blueskyAPI = "https://public.api.bsky.app/xrpc"
+
+// blueskyPathPattern matches a post URL and captures the author's handle
+// or DID and the post's record key.
+var blueskyPathPattern = regexp.MustCompile(`^/profile/([^/]+)/post/(\w+)/?$`)
+
+// blueskySite reads Bluesky posts, and the author's thread around them,
+// through the AppView's getPostThread.
+var blueskySite = SiteExtractor{
+	Name: "Bluesky",
+	Match: func(u *url.URL) bool {
+		return hostIs(u, "bsky.app", false) && blueskyPathPattern.MatchString(u.Path)
+	},
+	Extract: extractBluesky,
+}
+
+// bskyThread maps a thread view. Parent and replies the viewer can't
+// see (deleted or blocked posts) have no Post.
+type bskyThread struct {
+	Post    *bskyPost    `json:"post"`
+	Parent  *bskyThread  `json:"parent"`
+	Replies []bskyThread `json:"replies"`
+}
+
+type bskyAuthor struct {
+	DID         string `json:"did"`
+	Handle      string `json:"handle"`
+	DisplayName string `json:"displayName"`
+}
+
+// bskyPost maps a post view.
+type bskyPost struct {
+	URI    string     `json:"uri"`
+	Author bskyAuthor `json:"author"`
+	Record struct {
+		Text      string    `json:"text"`
+		CreatedAt time.Time `json:"createdAt"`
+		Langs     []string  `json:"langs"`
+	} `json:"record"`
+	Embed *bskyEmbed `json:"embed"`
+}
+
+// bskyEmbed maps the embed views: images, video, an external link card,
+// a quoted record, or a quoted record with media.
+type bskyEmbed struct {
+	Images []struct {
+		Alt string `json:"alt"`
+	} `json:"images"`
+	Alt      string `json:"alt"` // video
+	External *struct {
+		URI   string `json:"uri"`
+		Title string `json:"title"`
+	} `json:"external"`
+	Record *bskyEmbedRecord `json:"record"`
+	Media  *bskyEmbed       `json:"media"`
+}
+
+// bskyEmbedRecord is a quoted post. In a record-with-media embed it is
+// wrapped once more in Record.
+type bskyEmbedRecord struct {
+	Author *bskyAuthor `json:"author"`
+	Value  struct {
+		Text string `json:"text"`
+	} `json:"value"`
+	Record *bskyEmbedRecord `json:"record"`
+}
+
+// textParagraphs splits post text at blank lines.
+func textParagraphs(text string) []string {
+	var paragraphs []string
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return paragraphs
+}
+
+// paragraphs returns the post's text followed by its media alt text, a
+// link card's title and any quoted post.
+func (p *bskyPost) paragraphs() []string {
+	return append(textParagraphs(p.Record.Text), p.Embed.paragraphs()...)
+}
+
+func (e *bskyEmbed) paragraphs() []string {
+	if e == nil {
+		return nil
+	}
+	var paragraphs []string
+	for _, img := range e.Images {
+		if alt := strings.TrimSpace(img.Alt); alt != "" {
+			paragraphs = append(paragraphs, "Image: "+alt)
+		}
+	}
+	if alt := strings.TrimSpace(e.Alt); alt != "" {
+		paragraphs = append(paragraphs, "Video: "+alt)
+	}
+	if ext := e.External; ext != nil && ext.URI != "" {
+		link := ext.URI
+		if u, err := url.Parse(ext.URI); err == nil && u.Host != "" {
+			link = u.Hostname()
+		}
+		label := "Link to " + link
+		if title := strings.TrimSpace(ext.Title); title != "" {
+			label += ": " + title
+		}
+		paragraphs = append(paragraphs, label+".")
+	}
+	paragraphs = append(paragraphs, e.Media.paragraphs()...)
+	if r := e.Record; r != nil {
+		if r.Author == nil && r.Record != nil {
+			r = r.Record
+		}
+		if r.Author != nil {
+			paragraphs = append(paragraphs, quotedPost(r.Author.DisplayName, r.Author.Handle, textParagraphs(r.Value.Text))...)
+		}
+	}
+	return paragraphs
+}
+
+func extractBluesky(ctx context.Context, u *url.URL) (*URLResult, error) {
+	m := blueskyPathPattern.FindStringSubmatch(u.Path)
+	actor, rkey := m[1], m[2]
+
+	if !strings.HasPrefix(actor, "did:") {
+		var resolved struct {
+			DID string `json:"did"`
+		}
+		endpoint := blueskyAPI + "/com.atproto.identity.resolveHandle?" + url.Values{"handle": {actor}}.Encode()
+		if err := fetchJSON(ctx, endpoint, nil, &resolved); err != nil {
+			return nil, fmt.Errorf("resolve handle %s: %w", actor, err)
+		}
+		actor = resolved.DID
+	}
+
+	q := url.Values{
+		"uri":          {fmt.Sprintf("at://%s/app.bsky.feed.post/%s", actor, rkey)},
+		"depth":        {"100"},
+		"parentHeight": {"100"},
+	}
+	var resp struct {
+		Thread bskyThread `json:"thread"`
+	}
+	if err := fetchJSON(ctx, blueskyAPI+"/app.bsky.feed.getPostThread?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Thread.Post == nil {
+		return nil, fmt.Errorf("post not found or not public")
+	}
+
+	thread := blueskyThread(&resp.Thread)
+	posts := make([][]string, len(thread))
+	for i, p := range thread {
+		posts[i] = p.paragraphs()
+	}
+	root := thread[0]
+	meta := Metadata{
+		Byline:   strings.TrimSpace(fmt.Sprintf("%s (@%s)", root.Author.DisplayName, root.Author.Handle)),
+		SiteName: "Bluesky",
+		FinalURL: fmt.Sprintf("https://bsky.app/profile/%s/post/%s",
+			root.Author.Handle, root.URI[strings.LastIndex(root.URI, "/")+1:]),
+	}
+	if len(root.Record.Langs) > 0 {
+		meta.Language = root.Record.Langs[0]
+	}
+	if !root.Record.CreatedAt.IsZero() {
+		t := root.Record.CreatedAt.UTC()
+		meta.Published = &t
+	}
+	return postsResult(root.Author.Handle, posts, meta), nil
+}
+
+// blueskyThread returns the chain of self-replies t's post belongs to,
+// oldest first. Below the post, the author's earliest reply at each
+// level continues the thread.
+func blueskyThread(t *bskyThread) []*bskyPost {
+	author := t.Post.Author.DID
+	var ancestors []*bskyPost
+	for p := t.Parent; p != nil && p.Post != nil && p.Post.Author.DID == author; p = p.Parent {
+		if len(ancestors) >= maxThreadPosts {
+			break
+		}
+		ancestors = append(ancestors, p.Post)
+	}
+
+	thread := make([]*bskyPost, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		thread = append(thread, ancestors[i])
+	}
+	thread = append(thread, t.Post)
+
+	for cur := t; len(thread) < maxThreadPosts; {
+		var next *bskyThread
+		for i := range cur.Replies {
+			r := &cur.Replies[i]
+			if r.Post != nil && r.Post.Author.DID == author &&
+				(next == nil || r.Post.Record.CreatedAt.Before(next.Post.Record.CreatedAt)) {
+				next = r
+			}
+		}
+		if next == nil {
+			break
+		}
+		thread = append(thread, next.Post)
+		cur = next
+	}
+	return thread
+}
@@ -0,0 +1,20 @@
+package extractor
+
+import (
+	"context"
+	"io"
+)
+
+// ctxReader is an io.Reader that fails once its context is canceled, so
+// long copies and decoders stop at their next read.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
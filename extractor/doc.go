@@ -0,0 +1,757 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ExtractDOC reads a legacy Word document (.doc, Word 97 to 2003). The
+// file is an OLE2 compound file; its WordDocument stream holds the
+// text, in pieces listed by the piece table in the 0Table or 1Table
+// stream. Paragraph styles give headings, tables are read a row at a
+// time and the DOCXOptions in ctx apply as they do to .docx files.
+// Word 95 and older files, and encrypted ones, are rejected.
+func ExtractDOC(ctx context.Context, r io.Reader) (*Document, error) {
+	// A compound file is a file system of its own and has to be read
+	// whole. The whole file is held to the limit of a single DOCX part.
+	data, err := io.ReadAll(io.LimitReader(ctxReader{ctx, r}, maxDecompressed+1))
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	if len(data) > maxDecompressed {
+		return nil, fmt.Errorf("doc too large (over %d bytes)", maxDecompressed)
+	}
+	ole, err := parseOLE(data)
+	if err != nil {
+		return nil, fmt.Errorf("open doc: %w", err)
+	}
+	d, err := parseWordDoc(ctx, ole)
+	if err != nil {
+		return nil, err
+	}
+	opts := docxOptions(ctx)
+
+	// Footnote and endnote references are read as [1], [i], ... and
+	// comments where they are anchored, as in DOCX.
+	marks := map[int]string{}
+	var footnotes, endnotes []docxNote
+	if !opts.SkipNotes {
+		footnotes = d.notes(opts, marks, false, d.plc(fcPlcffndRef), 2, d.plc(fcPlcffndTxt), d.ccpText)
+		endnotes = d.notes(opts, marks, true, d.plc(fcPlcfendRef), 2, d.plc(fcPlcfendTxt), d.ednStart())
+	}
+	if opts.Comments {
+		d.comments(opts, marks)
+	}
+
+	var b docBuilder
+	var headers, footers []string
+	if opts.HeadersFooters {
+		headers, footers = d.headersFooters(opts)
+	}
+	for _, text := range headers {
+		b.paragraph(text)
+	}
+	var title string
+	rd := &docReader{d: d, opts: opts, marks: marks}
+	rd.read(0, d.ccpText, func(text string, p docParaRun) {
+		level := d.headingLevel(p.istd)
+		if p.outline > 0 {
+			level = p.outline
+			if level > 9 {
+				level = 0
+			}
+		}
+		switch {
+		case p.inTable:
+			b.paragraph(text)
+		case d.isTitle(p.istd) && title == "":
+			title = strings.TrimSpace(text)
+		case level > 0:
+			b.heading(level, text)
+		default:
+			b.paragraph(text)
+		}
+	})
+	for _, text := range footers {
+		b.paragraph(text)
+	}
+	addDOCNotes(&b, false, footnotes)
+	addDOCNotes(&b, true, endnotes)
+	doc := b.document(title)
+
+	// Document properties are optional, like DOCX's core.xml.
+	if si, err := ole.stream(ctx, "\x05SummaryInformation"); err == nil {
+		title, meta := parseSummaryInformation(si)
+		doc.Metadata = meta
+		if doc.Title == "" {
+			doc.Title = title
+		}
+	}
+	return doc, nil
+}
+
+// addDOCNotes adds the footnotes or endnotes of a .doc, numbered in
+// the order they are referenced.
+func addDOCNotes(b *docBuilder, endnotes bool, notes []docxNote) {
+	refs := make([]string, len(notes))
+	byID := make(map[string]docxNote, len(notes))
+	for i, n := range notes {
+		refs[i] = strconv.Itoa(i)
+		byID[refs[i]] = n
+	}
+	addDOCXNotes(b, endnotes, refs, byID)
+}
+
+// wordDoc is the WordDocument stream of a .doc file, with what the
+// table stream says about it.
+type wordDoc struct {
+	word, table []byte
+	fcLcb       []byte // FibRgFcLcb: where structures are in the table stream
+
+	// Character counts of the stories, which follow each other in CP
+	// (character position) order: main text, footnotes, headers,
+	// comments, endnotes, ...
+	ccpText, ccpFtn, ccpHdd, ccpMcr, ccpAtn int
+	cpEnd                                   int // end of the last story
+
+	// emitted counts the characters each has read, which a crafted
+	// piece table could otherwise make far more than the file holds.
+	emitted int
+
+	pieces []docPiece
+	chars  []docCharRun
+	paras  []docParaRun
+	styles []docStyle
+}
+
+// docPiece is a piece table entry: a run of CPs stored contiguously,
+// as cp1252 bytes when compressed or UTF-16 otherwise, from byte fc of
+// the WordDocument stream.
+type docPiece struct {
+	start, end int
+	fc         int
+	compressed bool
+}
+
+// docCharRun is the character properties we read, for the text between
+// two stream offsets.
+type docCharRun struct {
+	start, end        uint32
+	deleted, inserted bool // tracked changes
+}
+
+// docParaRun is the paragraph properties we read, for the paragraph
+// whose text lies between two stream offsets.
+type docParaRun struct {
+	start, end uint32
+	istd       int  // paragraph style
+	outline    int  // outline level + 1; 0 if unset
+	inTable    bool // a paragraph in a table cell
+	rowEnd     bool // the mark ending a table row
+	header     bool // ... of a header row
+}
+
+// docStyle is a stylesheet entry.
+type docStyle struct {
+	sti  int // built-in style identifier
+	base int // istd of the style it is based on
+}
+
+// Built-in style identifiers.
+const (
+	stiHeading1 = 1
+	stiHeading9 = 9
+	stiTitle    = 62
+)
+
+// Indexes of the FibRgFcLcb entries we use, each an offset and size in
+// the table stream.
+const (
+	fcStshf           = 1
+	fcPlcffndRef      = 2
+	fcPlcffndTxt      = 3
+	fcPlcfandRef      = 4
+	fcPlcfandTxt      = 5
+	fcPlcfHdd         = 11
+	fcPlcfBteChpx     = 12
+	fcPlcfBtePapx     = 13
+	fcClx             = 33
+	fcGrpXstAtnOwners = 36
+	fcPlcfendRef      = 46
+	fcPlcfendTxt      = 47
+)
+
+// Property modifiers (sprms) we read.
+const (
+	sprmCFRMarkDel   = 0x0800
+	sprmCFRMarkIns   = 0x0801
+	sprmPFInTable    = 0x2416
+	sprmPFTtp        = 0x2417
+	sprmPOutLvl      = 0x2640
+	sprmTTableHeader = 0x3404
+	sprmPChgTabs     = 0xC615
+	sprmTDefTable    = 0xD608
+)
+
+// parseWordDoc reads the File Information Block at the start of the
+// WordDocument stream, the piece table and the formatting we use.
+func parseWordDoc(ctx context.Context, ole *oleFile) (*wordDoc, error) {
+	word, err := ole.stream(ctx, "WordDocument")
+	if err != nil {
+		return nil, fmt.Errorf("open doc: %w", err)
+	}
+	if len(word) < 34 || le.Uint16(word) != 0xA5EC {
+		return nil, errors.New("not a Word document")
+	}
+	if nFib := le.Uint16(word[2:]); nFib < 0xC1 {
+		return nil, errors.New(
+			".doc from Word 95 or older is not supported — please save as .docx and try again")
+	}
+	flags := le.Uint16(word[0x0A:])
+	if flags&0x0100 != 0 {
+		return nil, errors.New(".doc is password-protected — please remove the password and try again")
+	}
+	tableName := "0Table"
+	if flags&0x0200 != 0 {
+		tableName = "1Table"
+	}
+	table, err := ole.stream(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("open doc: %w", err)
+	}
+
+	// FibBase is followed by three arrays, each after its count: 16-bit
+	// values, 32-bit values (the story lengths) and offset/size pairs.
+	d := &wordDoc{word: word, table: table}
+	pos := 32
+	pos += 2 + 2*int(le.Uint16(word[pos:]))
+	if pos+2 > len(word) {
+		return nil, fmt.Errorf("open doc: %w", errOLECorrupt)
+	}
+	lw := byteRange(word, pos+2, 4*int(le.Uint16(word[pos:])))
+	pos += 2 + len(lw)
+	if pos+2 > len(word) {
+		return nil, fmt.Errorf("open doc: %w", errOLECorrupt)
+	}
+	d.fcLcb = byteRange(word, pos+2, 8*int(le.Uint16(word[pos:])))
+	ccp := func(i int) int {
+		if 4*i+4 > len(lw) {
+			return 0
+		}
+		return max(int(int32(le.Uint32(lw[4*i:]))), 0)
+	}
+	d.ccpText, d.ccpFtn, d.ccpHdd, d.ccpMcr, d.ccpAtn = ccp(3), ccp(4), ccp(5), ccp(6), ccp(7)
+	// After the main text come the footnote, header, macro, comment,
+	// endnote, text box and header text box stories; when any of those
+	// is present, the document ends with one more paragraph mark.
+	for i := 3; i <= 10; i++ {
+		d.cpEnd += ccp(i)
+	}
+	if d.cpEnd > d.ccpText {
+		d.cpEnd++
+	}
+
+	if err := d.readPieces(); err != nil {
+		return nil, err
+	}
+	// Formatting is optional: without it the text reads as plain
+	// paragraphs.
+	for _, page := range d.fkpPages(d.plc(fcPlcfBteChpx)) {
+		d.chars = append(d.chars, parseCHPXPage(page)...)
+	}
+	for _, page := range d.fkpPages(d.plc(fcPlcfBtePapx)) {
+		d.paras = append(d.paras, parsePAPXPage(page)...)
+	}
+	sort.Slice(d.chars, func(i, j int) bool { return d.chars[i].start < d.chars[j].start })
+	sort.Slice(d.paras, func(i, j int) bool { return d.paras[i].start < d.paras[j].start })
+	d.styles = parseStylesheet(d.plc(fcStshf))
+	return d, nil
+}
+
+// plc returns the table stream structure at FibRgFcLcb entry i, or nil.
+func (d *wordDoc) plc(i int) []byte {
+	if 8*i+8 > len(d.fcLcb) {
+		return nil
+	}
+	fc := int(le.Uint32(d.fcLcb[8*i:]))
+	lcb := int(le.Uint32(d.fcLcb[8*i+4:]))
+	if lcb == 0 {
+		return nil
+	}
+	return byteRange(d.table, fc, lcb)
+}
+
+// ednStart is the CP the endnote story starts at.
+func (d *wordDoc) ednStart() int {
+	return d.ccpText + d.ccpFtn + d.ccpHdd + d.ccpMcr + d.ccpAtn
+}
+
+// readPieces reads the piece table from the Clx, which starts with
+// property modifiers for the pieces that we skip.
+func (d *wordDoc) readPieces() error {
+	clx := d.plc(fcClx)
+	for len(clx) >= 3 && clx[0] == 0x01 { // Prc
+		clx = clx[min(3+int(le.Uint16(clx[1:])), len(clx)):]
+	}
+	if len(clx) >= 5 && clx[0] == 0x02 { // Pcdt
+		plcPcd := byteRange(clx, 5, int(le.Uint32(clx[1:])))
+		cps := plcCPs(plcPcd, 8)
+		// Pieces must tile the stories in order: overlapping or
+		// backward pieces would read the same bytes over and over.
+		if len(cps) > 0 && cps[0] != 0 {
+			return fmt.Errorf("open doc: %w", errOLECorrupt)
+		}
+		for i := 0; i+1 < len(cps); i++ {
+			if cps[i+1] <= cps[i] || cps[i+1] > d.cpEnd {
+				return fmt.Errorf("open doc: %w", errOLECorrupt)
+			}
+		}
+		for i := 0; i+1 < len(cps); i++ {
+			pcd := plcPcd[4*len(cps)+8*i:]
+			fc := le.Uint32(pcd[2:])
+			p := docPiece{start: cps[i], end: cps[i+1], fc: int(fc & 0x3FFFFFFF)}
+			if fc&0x40000000 != 0 {
+				p.compressed, p.fc = true, p.fc/2
+			}
+			d.pieces = append(d.pieces, p)
+		}
+	}
+	if len(d.pieces) == 0 {
+		return errors.New("open doc: no text found")
+	}
+	return nil
+}
+
+// plcCPs returns the CPs of a PLC, a table of n+1 CPs followed by n
+// entries of dataSize bytes each.
+func plcCPs(plc []byte, dataSize int) []int {
+	if len(plc) < 4 {
+		return nil
+	}
+	n := (len(plc) - 4) / (4 + dataSize)
+	cps := make([]int, n+1)
+	for i := range cps {
+		cps[i] = int(int32(le.Uint32(plc[4*i:])))
+	}
+	return cps
+}
+
+// fkpPages returns the 512-byte formatted disk pages listed in a bin
+// table (PlcBteChpx or PlcBtePapx).
+func (d *wordDoc) fkpPages(plc []byte) [][]byte {
+	cps := plcCPs(plc, 4)
+	if len(cps) < 2 {
+		return nil
+	}
+	n := len(cps) - 1
+	var pages [][]byte
+	for i := 0; i < n; i++ {
+		pn := int(le.Uint32(plc[4*(n+1)+4*i:]) & 0x3FFFFF)
+		if page := byteRange(d.word, pn*512, 512); page != nil {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// parseCHPXPage reads the character runs of a CHPX page: crun in the
+// last byte, crun+1 offsets, then a byte per run locating its
+// properties in words.
+func parseCHPXPage(page []byte) []docCharRun {
+	crun := int(page[511])
+	if 5*crun+4 > 511 {
+		return nil
+	}
+	runs := make([]docCharRun, crun)
+	for i := range runs {
+		run := docCharRun{start: le.Uint32(page[4*i:]), end: le.Uint32(page[4*i+4:])}
+		if off := 2 * int(page[4*(crun+1)+i]); off > 0 && off < 511 {
+			eachSprm(byteRange(page, off+1, int(page[off])), func(op uint16, arg []byte) {
+				switch op {
+				case sprmCFRMarkDel:
+					run.deleted = arg[0]&1 != 0
+				case sprmCFRMarkIns:
+					run.inserted = arg[0]&1 != 0
+				}
+			})
+		}
+		runs[i] = run
+	}
+	return runs
+}
+
+// parsePAPXPage reads the paragraph runs of a PAPX page, which is laid
+// out as a CHPX page with 13-byte entries after the offsets. A PAPX is
+// a style followed by modifiers.
+func parsePAPXPage(page []byte) []docParaRun {
+	crun := int(page[511])
+	if 17*crun+4 > 511 {
+		return nil
+	}
+	runs := make([]docParaRun, crun)
+	for i := range runs {
+		run := docParaRun{start: le.Uint32(page[4*i:]), end: le.Uint32(page[4*i+4:])}
+		if off := 2 * int(page[4*(crun+1)+13*i]); off > 0 && off < 510 {
+			var papx []byte
+			if cb := int(page[off]); cb > 0 {
+				papx = byteRange(page, off+1, 2*cb-1)
+			} else {
+				papx = byteRange(page, off+2, 2*int(page[off+1]))
+			}
+			if len(papx) >= 2 {
+				run.istd = int(le.Uint16(papx))
+				eachSprm(papx[2:], func(op uint16, arg []byte) {
+					switch op {
+					case sprmPFInTable:
+						run.inTable = arg[0] != 0
+					case sprmPFTtp:
+						run.rowEnd = arg[0] != 0
+					case sprmPOutLvl:
+						run.outline = int(arg[0]) + 1
+					case sprmTTableHeader:
+						run.header = arg[0] != 0
+					}
+				})
+			}
+		}
+		runs[i] = run
+	}
+	return runs
+}
+
+// eachSprm calls fn with each property modifier in grpprl. The operand
+// size is in the opcode, or before the operand for variable sizes.
+func eachSprm(grpprl []byte, fn func(op uint16, arg []byte)) {
+	for len(grpprl) >= 2 {
+		op := le.Uint16(grpprl)
+		grpprl = grpprl[2:]
+		var n int
+		switch op >> 13 {
+		case 0, 1:
+			n = 1
+		case 2, 4, 5:
+			n = 2
+		case 3:
+			n = 4
+		case 7:
+			n = 3
+		case 6:
+			switch {
+			case op == sprmTDefTable && len(grpprl) >= 2:
+				n = int(le.Uint16(grpprl)) + 1
+			case op == sprmPChgTabs && len(grpprl) >= 1 && grpprl[0] == 255:
+				return // sized by its contents; nothing we read follows
+			case len(grpprl) >= 1:
+				n = int(grpprl[0]) + 1
+			default:
+				return
+			}
+		}
+		if n > len(grpprl) {
+			return
+		}
+		fn(op, grpprl[:n])
+		grpprl = grpprl[n:]
+	}
+}
+
+// parseStylesheet reads the styles' built-in identifiers and bases
+// from the STSH.
+func parseStylesheet(stsh []byte) []docStyle {
+	if len(stsh) < 4 {
+		return nil
+	}
+	cbStshi := int(le.Uint16(stsh))
+	if cbStshi < 2 {
+		return nil
+	}
+	cstd := int(le.Uint16(stsh[2:]))
+	var styles []docStyle
+	for i, pos := 0, 2+cbStshi; i < cstd && pos+2 <= len(stsh); i++ {
+		cb := int(le.Uint16(stsh[pos:]))
+		std := byteRange(stsh, pos+2, cb)
+		pos += 2 + cb
+		st := docStyle{sti: -1, base: -1}
+		if len(std) >= 4 {
+			st.sti = int(le.Uint16(std) & 0x0FFF)
+			st.base = int(le.Uint16(std[2:]) >> 4)
+		}
+		styles = append(styles, st)
+	}
+	return styles
+}
+
+// headingLevel returns the heading level of paragraphs in style istd:
+// N for the built-in "heading N" styles and styles based on them.
+func (d *wordDoc) headingLevel(istd int) int {
+	for i := 0; i < maxStyleDepth && istd >= 0 && istd < len(d.styles); i++ {
+		st := d.styles[istd]
+		if st.sti >= stiHeading1 && st.sti <= stiHeading9 {
+			return st.sti
+		}
+		istd = st.base
+	}
+	return 0
+}
+
+// isTitle reports whether style istd is the built-in Title style.
+func (d *wordDoc) isTitle(istd int) bool {
+	return istd >= 0 && istd < len(d.styles) && d.styles[istd].sti == stiTitle
+}
+
+// charRun returns the character properties of the text at offset fc.
+func (d *wordDoc) charRun(fc int) docCharRun {
+	i := sort.Search(len(d.chars), func(i int) bool { return d.chars[i].end > uint32(fc) })
+	if i < len(d.chars) && d.chars[i].start <= uint32(fc) {
+		return d.chars[i]
+	}
+	return docCharRun{}
+}
+
+// paraRun returns the properties of the paragraph whose mark is at
+// offset fc.
+func (d *wordDoc) paraRun(fc int) docParaRun {
+	i := sort.Search(len(d.paras), func(i int) bool { return d.paras[i].end > uint32(fc) })
+	if i < len(d.paras) && d.paras[i].start <= uint32(fc) {
+		return d.paras[i]
+	}
+	return docParaRun{}
+}
+
+// each calls fn with the characters from CP from to to, and where each
+// is stored in the WordDocument stream. Reading stops once the document
+// has produced maxDecompressed characters in all.
+func (d *wordDoc) each(from, to int, fn func(cp, fc int, c rune)) {
+	for _, pc := range d.pieces {
+		for cp := max(from, pc.start); cp < min(to, pc.end); cp++ {
+			if d.emitted++; d.emitted > maxDecompressed {
+				return
+			}
+			i := cp - pc.start
+			if pc.compressed {
+				fc := pc.fc + i
+				if fc >= len(d.word) {
+					return
+				}
+				fn(cp, fc, charmap.Windows1252.DecodeByte(d.word[fc]))
+				continue
+			}
+			fc := pc.fc + 2*i
+			if fc+2 > len(d.word) {
+				return
+			}
+			c := rune(le.Uint16(d.word[fc:]))
+			if utf16.IsSurrogate(c) && cp+1 < min(to, pc.end) && fc+4 <= len(d.word) {
+				if r := utf16.DecodeRune(c, rune(le.Uint16(d.word[fc+2:]))); r != utf8.RuneError {
+					fn(cp, fc, r)
+					cp++
+					continue
+				}
+			}
+			fn(cp, fc, c)
+		}
+	}
+}
+
+// notes reads the footnotes or endnotes, given the PLC of their
+// references in the main text, whose entries are dataSize bytes, and
+// the PLC of their text in the story starting at CP start. The marker
+// each reference is read with goes in marks.
+func (d *wordDoc) notes(opts DOCXOptions, marks map[int]string, endnotes bool, refPlc []byte, dataSize int, txtPlc []byte, start int) []docxNote {
+	refs := plcCPs(refPlc, dataSize)
+	texts := plcCPs(txtPlc, 0)
+	var notes []docxNote
+	for i := 0; i+1 < len(refs); i++ {
+		var text string
+		if i+1 < len(texts) {
+			text = d.storyText(opts, start+texts[i], start+texts[i+1])
+		}
+		notes = append(notes, docxNote{text: text})
+		marks[refs[i]] = "[" + noteNumber(endnotes, i+1) + "]"
+	}
+	return notes
+}
+
+// comments puts the reviewer comments in marks, to be read at their
+// anchors. Each reference carries the index of its author in the list
+// of comment authors.
+func (d *wordDoc) comments(opts DOCXOptions, marks map[int]string) {
+	const atrdSize = 30
+	refPlc := d.plc(fcPlcfandRef)
+	refs := plcCPs(refPlc, atrdSize)
+	texts := plcCPs(d.plc(fcPlcfandTxt), 0)
+
+	var authors []string
+	owners := d.plc(fcGrpXstAtnOwners)
+	for len(owners) >= 2 {
+		n := 2 * int(le.Uint16(owners))
+		name := byteRange(owners, 2, n)
+		if name == nil {
+			break
+		}
+		authors = append(authors, decodeUTF16(name))
+		owners = owners[2+n:]
+	}
+
+	start := d.ccpText + d.ccpFtn + d.ccpHdd + d.ccpMcr
+	for i := 0; i+1 < len(refs) && i+1 < len(texts); i++ {
+		text := d.storyText(opts, start+texts[i], start+texts[i+1])
+		if text == "" {
+			continue
+		}
+		c := docxNote{text: text}
+		if ibst := int(le.Uint16(refPlc[4*len(refs)+atrdSize*i+20:])); ibst < len(authors) {
+			c.author = authors[ibst]
+		}
+		marks[refs[i]] = commentText(c)
+	}
+}
+
+// headersFooters returns the distinct texts of the page headers and
+// footers. The header story starts with six note separators; then each
+// section has an even and odd header, even and odd footer, and first
+// page header and footer.
+func (d *wordDoc) headersFooters(opts DOCXOptions) (headers, footers []string) {
+	cps := plcCPs(d.plc(fcPlcfHdd), 0)
+	start := d.ccpText + d.ccpFtn
+	seen := map[string]bool{}
+	for i := 6; i+1 < len(cps); i++ {
+		text := d.storyText(opts, start+cps[i], start+cps[i+1])
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		switch (i - 6) % 6 {
+		case 0, 1, 4:
+			headers = append(headers, text)
+		default:
+			footers = append(footers, text)
+		}
+	}
+	return headers, footers
+}
+
+// storyText reads the text from CP from to to as one paragraph.
+func (d *wordDoc) storyText(opts DOCXOptions, from, to int) string {
+	var parts []string
+	rd := &docReader{d: d, opts: opts}
+	rd.read(from, to, func(text string, _ docParaRun) {
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	})
+	return strings.Join(parts, " ")
+}
+
+// docReader turns the characters of a story into paragraphs. Word marks
+// structure with control characters in the text: paragraph and cell
+// ends, fields (a code, then the result shown), note references, ...
+type docReader struct {
+	d      *wordDoc
+	opts   DOCXOptions
+	marks  map[int]string // text read at a note or comment reference, by CP
+	text   strings.Builder
+	fields []bool // open fields; true while reading the field code
+	table  *docxTable
+}
+
+// read reads the text from CP from to to, calling para with each
+// paragraph and its properties. A table row is one paragraph.
+func (r *docReader) read(from, to int, para func(text string, p docParaRun)) {
+	r.d.each(from, to, func(cp, fc int, c rune) {
+		run := r.d.charRun(fc)
+		if run.deleted && !r.opts.Original || run.inserted && r.opts.Original {
+			return
+		}
+		switch c {
+		case 0x13: // field begin
+			r.fields = append(r.fields, true)
+			return
+		case 0x14: // field separator: the result follows
+			if n := len(r.fields); n > 0 {
+				r.fields[n-1] = false
+			}
+			return
+		case 0x15: // field end
+			if n := len(r.fields); n > 0 {
+				r.fields = r.fields[:n-1]
+			}
+			return
+		}
+		for _, code := range r.fields {
+			if code {
+				return
+			}
+		}
+
+		switch c {
+		case '\r', '\f': // paragraph end; page or section break
+			p := r.d.paraRun(fc)
+			if p.inTable && c == '\r' {
+				r.text.WriteByte('\n')
+				return
+			}
+			r.table = nil
+			para(r.text.String(), p)
+			r.text.Reset()
+		case 0x07: // cell or row end
+			p := r.d.paraRun(fc)
+			if r.table == nil {
+				r.table = &docxTable{}
+			}
+			if p.rowEnd {
+				r.table.headerRow = p.header
+				para(r.table.endRow(), docParaRun{inTable: true})
+				r.table.cells = nil
+			} else {
+				r.table.cells = append(r.table.cells, strings.Join(strings.Fields(r.text.String()), " "))
+			}
+			r.text.Reset()
+		case 0x0B: // line break
+			r.text.WriteByte('\n')
+		case '\t':
+			r.text.WriteByte('\t')
+		case 0x1E: // non-breaking hyphen
+			r.text.WriteByte('-')
+		case 0xA0:
+			r.text.WriteByte(' ')
+		case 0x02, 0x05: // auto-numbered note reference, comment anchor
+			r.text.WriteString(r.marks[cp])
+		default:
+			// Pictures, drawings, optional hyphens and other marks.
+			if c >= 0x20 {
+				r.text.WriteRune(c)
+			}
+		}
+	})
+	if r.text.Len() > 0 {
+		para(r.text.String(), docParaRun{})
+		r.text.Reset()
+	}
+}
+
+// byteRange returns b[off:off+n], or nil if that is out of range.
+func byteRange(b []byte, off, n int) []byte {
+	if off < 0 || n < 0 || off > len(b) || n > len(b)-off {
+		return nil
+	}
+	return b[off : off+n]
+}
+
+// decodeUTF16 decodes UTF-16LE text.
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = le.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
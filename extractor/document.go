@@ -0,0 +1,131 @@
+package extractor
+
+import "strings"
+
+// maxSectionLevel is the deepest heading level that starts a new
+// section. Deeper headings (HTML <h4>, Word "Heading 4", ...) are kept
+// as ordinary paragraphs so chapter navigation stays coarse enough to
+// be useful.
+const maxSectionLevel = 3
+
+// Document is the structured form of extracted content: a title and the
+// sections that make it up, in reading order.
+type Document struct {
+	Title    string    `json:"title,omitempty"`
+	Sections []Section `json:"sections"`
+
+	// Metadata is what the file says about itself. It is reported next
+	// to the document rather than inside it.
+	Metadata Metadata `json:"-"`
+}
+
+// Section is a run of paragraphs under an optional heading. Level is the
+// heading depth (1 for a top-level chapter); the leading section of a
+// document before any heading has no heading and level 0.
+type Section struct {
+	Heading    string   `json:"heading,omitempty"`
+	Level      int      `json:"level,omitempty"`
+	Paragraphs []string `json:"paragraphs"`
+}
+
+// Text flattens the document into plain text. Headings are emitted as
+// their own line, paragraphs are separated by a newline and sections by
+// a blank line.
+func (d *Document) Text() string {
+	var sections []string
+	for _, s := range d.Sections {
+		lines := make([]string, 0, len(s.Paragraphs)+1)
+		if s.Heading != "" {
+			lines = append(lines, s.Heading)
+		}
+		lines = append(lines, s.Paragraphs...)
+		if len(lines) > 0 {
+			sections = append(sections, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// docBuilder accumulates paragraphs and headings into a Document.
+type docBuilder struct {
+	doc Document
+}
+
+// heading starts a new section. Levels deeper than maxSectionLevel are
+// added as a paragraph instead.
+func (b *docBuilder) heading(level int, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if level < 1 || level > maxSectionLevel {
+		b.paragraph(text)
+		return
+	}
+	// Reuse an empty section left by breakSection.
+	if n := len(b.doc.Sections); n > 0 && isEmptySection(b.doc.Sections[n-1]) {
+		b.doc.Sections = b.doc.Sections[:n-1]
+	}
+	b.doc.Sections = append(b.doc.Sections, Section{Heading: text, Level: level})
+}
+
+// paragraph appends text to the current section, starting an untitled
+// one if the document is still empty.
+func (b *docBuilder) paragraph(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if len(b.doc.Sections) == 0 {
+		b.doc.Sections = append(b.doc.Sections, Section{})
+	}
+	s := &b.doc.Sections[len(b.doc.Sections)-1]
+	s.Paragraphs = append(s.Paragraphs, text)
+}
+
+// breakSection makes the next paragraph start a fresh untitled section,
+// e.g. at an EPUB chapter boundary that has no heading of its own.
+func (b *docBuilder) breakSection() {
+	n := len(b.doc.Sections)
+	if n == 0 {
+		return
+	}
+	if isEmptySection(b.doc.Sections[n-1]) {
+		return
+	}
+	b.doc.Sections = append(b.doc.Sections, Section{})
+}
+
+// document returns the built document, dropping an empty trailing
+// section left by breakSection.
+func (b *docBuilder) document(title string) *Document {
+	doc := b.doc
+	if n := len(doc.Sections); n > 0 && isEmptySection(doc.Sections[n-1]) {
+		doc.Sections = doc.Sections[:n-1]
+	}
+	if doc.Sections == nil {
+		doc.Sections = []Section{}
+	}
+	// Keep "paragraphs" an array in JSON even for heading-only sections.
+	for i := range doc.Sections {
+		if doc.Sections[i].Paragraphs == nil {
+			doc.Sections[i].Paragraphs = []string{}
+		}
+	}
+	doc.Title = strings.TrimSpace(title)
+	return &doc
+}
+
+func isEmptySection(s Section) bool {
+	return s.Heading == "" && len(s.Paragraphs) == 0
+}
+
+// TextDocument builds a Document from plain text, treating blank lines
+// as paragraph breaks.
+func TextDocument(title, text string) *Document {
+	var b docBuilder
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		b.paragraph(para)
+	}
+	return b.document(title)
+}
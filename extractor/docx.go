@@ -2,121 +2,707 @@ package extractor
 
 import (
 	"archive/zip"
+	"cmp"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
-	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// ExtractDOCX reads a .docx file from an io.Reader and returns plain text.
-// A .docx file is a ZIP archive containing word/document.xml with the text.
-func ExtractDOCX(r io.Reader) (string, error) {
-	// Write to temp file since zip.NewReader needs a ReaderAt.
-	tmp, err := os.CreateTemp("", "read-aloud-*.docx")
+// DOCXOptions choose which parts of a Word document are read besides
+// the body, and how tracked changes are resolved. The zero value reads
+// footnotes and endnotes but not page headers and footers, which mostly
+// repeat a title or page number, and reads the text with every tracked
+// change accepted.
+type DOCXOptions struct {
+	SkipNotes      bool // leave out footnotes and endnotes
+	HeadersFooters bool // read page headers before the body and footers after it
+	Original       bool // read the text as it was before tracked changes
+	Comments       bool // read reviewer comments where they are anchored
+}
+
+type docxOptionsKey struct{}
+
+// WithDOCXOptions returns a context under which Word documents are read
+// with opts, whether uploaded or downloaded from a link.
+func WithDOCXOptions(ctx context.Context, opts DOCXOptions) context.Context {
+	return context.WithValue(ctx, docxOptionsKey{}, opts)
+}
+
+// docxOptions returns the DOCXOptions in ctx, or the zero value.
+func docxOptions(ctx context.Context) DOCXOptions {
+	opts, _ := ctx.Value(docxOptionsKey{}).(DOCXOptions)
+	return opts
+}
+
+// ExtractDOCX reads a .docx file from an io.Reader and returns its
+// content as a Document. A .docx file is a ZIP archive containing
+// word/document.xml with the text, and further parts for notes,
+// headers and footers, read as DOCXOptions in ctx say. Canceling ctx
+// stops the copy and the XML decode at their next read.
+func ExtractDOCX(ctx context.Context, r io.Reader) (*Document, error) {
+	zr, err := openZip(ctx, r, "read-aloud-*.docx")
 	if err != nil {
-		return "", fmt.Errorf("create temp file: %w", err)
+		return nil, err
 	}
-	defer os.Remove(tmp.Name())
-	defer tmp.Close()
+	defer zr.Close()
+	opts := docxOptions(ctx)
 
-	size, err := io.Copy(tmp, r)
-	if err != nil {
-		return "", fmt.Errorf("write temp file: %w", err)
+	// Find word/document.xml in the archive.
+	docFile := zr.find("word/document.xml")
+	if docFile == nil {
+		return nil, fmt.Errorf("word/document.xml not found in docx")
 	}
-	tmp.Close()
 
-	f, err := os.Open(tmp.Name())
-	if err != nil {
-		return "", fmt.Errorf("open temp file: %w", err)
+	var b docBuilder
+	if opts.HeadersFooters {
+		for _, text := range docxPartsText(ctx, zr, "header", opts) {
+			b.paragraph(text)
+		}
 	}
-	defer f.Close()
 
-	zr, err := zip.NewReader(f, size)
+	// Guard against zip bombs: openZipEntry rejects entries whose
+	// uncompressed size exceeds maxDecompressed.
+	rc, err := openZipEntry(docFile)
 	if err != nil {
-		return "", fmt.Errorf("open zip: %w", err)
+		return nil, err
 	}
+	defer rc.Close()
 
-	// Find word/document.xml in the archive.
-	var docFile *zip.File
+	p := newDOCXParser(&b, opts)
+	// Styles and list numbering are optional: without them headings
+	// are recognized by the built-in style IDs and lists go unnumbered.
+	if zf := zr.find("word/styles.xml"); zf != nil {
+		p.styles, _ = readDOCXPart(ctx, zf, parseStylesXML)
+	}
+	if zf := zr.find("word/numbering.xml"); zf != nil {
+		p.numbering, _ = readDOCXPart(ctx, zf, parseNumberingXML)
+	}
+	if opts.Comments {
+		p.comments = readDOCXNotes(ctx, zr, "word/comments.xml", opts)
+	}
+	if err := p.parse(ctxReader{ctx, rc}); err != nil {
+		return nil, err
+	}
+
+	if opts.HeadersFooters {
+		for _, text := range docxPartsText(ctx, zr, "footer", opts) {
+			b.paragraph(text)
+		}
+	}
+	if !opts.SkipNotes {
+		addDOCXNotes(&b, false, p.footnoteRefs, readDOCXNotes(ctx, zr, "word/footnotes.xml", opts))
+		addDOCXNotes(&b, true, p.endnoteRefs, readDOCXNotes(ctx, zr, "word/endnotes.xml", opts))
+	}
+	doc := b.document(p.title)
+
+	// Document properties are optional; a broken core.xml only loses
+	// the metadata.
+	if coreFile := zr.find("docProps/core.xml"); coreFile != nil {
+		if rc, err := openZipEntry(coreFile); err == nil {
+			title, meta, err := parseCoreXML(ctxReader{ctx, rc})
+			rc.Close()
+			if err == nil {
+				doc.Metadata = meta
+				if doc.Title == "" {
+					doc.Title = title
+				}
+			}
+		}
+	}
+
+	// The runs' w:lang describe the text itself, so they win over
+	// core.xml's dc:language. Runs without one are in the document
+	// default language from styles.xml.
+	langs := p.langs
+	if p.styles != nil && p.styles.lang != "" {
+		langs[p.styles.lang] += langs[""]
+	}
+	delete(langs, "")
+	if lang := langs.dominant(); lang != "" {
+		doc.Metadata.Language = lang
+	}
+	return doc, nil
+}
+
+// docxPartPattern matches header and footer part names; Word numbers
+// them header1.xml, header2.xml, ... in no particular order.
+var docxPartPattern = regexp.MustCompile(`^word/(header|footer)(\d+)\.xml$`)
+
+// docxPartsText returns the paragraphs of every header or footer part
+// (kind is "header" or "footer"), without repeats: the first-page, even
+// and default variants often say the same thing. Unreadable parts are
+// skipped.
+func docxPartsText(ctx context.Context, zr *zipArchive, kind string, opts DOCXOptions) []string {
+	type part struct {
+		n  int
+		zf *zip.File
+	}
+	var parts []part
 	for _, zf := range zr.File {
-		if zf.Name == "word/document.xml" {
-			docFile = zf
-			break
+		if m := docxPartPattern.FindStringSubmatch(zf.Name); m != nil && m[1] == kind {
+			n, _ := strconv.Atoi(m[2])
+			parts = append(parts, part{n, zf})
 		}
 	}
-	if docFile == nil {
-		return "", fmt.Errorf("word/document.xml not found in docx")
+	sort.Slice(parts, func(i, j int) bool { return parts[i].n < parts[j].n })
+
+	var texts []string
+	seen := map[string]bool{}
+	for _, pt := range parts {
+		rc, err := openZipEntry(pt.zf)
+		if err != nil {
+			continue
+		}
+		var b docBuilder
+		err = newDOCXParser(&b, DOCXOptions{SkipNotes: true, Original: opts.Original}).parse(ctxReader{ctx, rc})
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		for _, s := range b.document("").Sections {
+			for _, text := range append([]string{s.Heading}, s.Paragraphs...) {
+				if text != "" && !seen[text] {
+					seen[text] = true
+					texts = append(texts, text)
+				}
+			}
+		}
 	}
+	return texts
+}
+
+// docxNote is a footnote, endnote or comment.
+type docxNote struct {
+	author string // of a comment
+	text   string
+}
 
-	// Guard against zip bombs: reject if uncompressed size exceeds 50 MB.
-	const maxDecompressed = 50 << 20
-	if docFile.UncompressedSize64 > maxDecompressed {
-		return "", fmt.Errorf("document.xml too large (%d bytes)", docFile.UncompressedSize64)
+// readDOCXNotes reads footnotes.xml, endnotes.xml or comments.xml and
+// returns each note by ID, or nil if the part is missing or broken.
+// Tracked changes in the notes are resolved as opts say.
+func readDOCXNotes(ctx context.Context, zr *zipArchive, name string, opts DOCXOptions) map[string]docxNote {
+	zf := zr.find(name)
+	if zf == nil {
+		return nil
+	}
+	rc, err := openZipEntry(zf)
+	if err != nil {
+		return nil
 	}
+	defer rc.Close()
+	p := newDOCXParser(&docBuilder{}, DOCXOptions{SkipNotes: true, Original: opts.Original})
+	p.notes = map[string][]string{}
+	p.authors = map[string]string{}
+	if err := p.parse(ctxReader{ctx, rc}); err != nil {
+		return nil
+	}
+	notes := make(map[string]docxNote, len(p.notes))
+	for id, paras := range p.notes {
+		notes[id] = docxNote{author: p.authors[id], text: strings.Join(paras, " ")}
+	}
+	return notes
+}
 
-	rc, err := docFile.Open()
+// addDOCXNotes adds a "Footnotes" or "Endnotes" section, numbered in
+// the order refs first cite the notes, as the markers in the text are.
+func addDOCXNotes(b *docBuilder, endnotes bool, refs []string, notes map[string]docxNote) {
+	heading := "Footnotes"
+	if endnotes {
+		heading = "Endnotes"
+	}
+	started := false
+	for i, id := range refs {
+		text := notes[id].text
+		if text == "" {
+			continue
+		}
+		if !started {
+			b.heading(1, heading)
+			started = true
+		}
+		b.paragraph(noteNumber(endnotes, i+1) + ". " + text)
+	}
+}
+
+// noteNumber is the number of the nth footnote or endnote: 1, 2, ...
+// for footnotes and i, ii, ... for endnotes, as Word numbers them by
+// default.
+func noteNumber(endnote bool, n int) string {
+	if endnote {
+		return romanNumeral(n, false)
+	}
+	return strconv.Itoa(n)
+}
+
+// romanNumeral returns n in Roman numerals, lower-case unless upper.
+func romanNumeral(n int, upper bool) string {
+	if n <= 0 || n >= 4000 {
+		return strconv.Itoa(n)
+	}
+	var sb strings.Builder
+	for _, d := range []struct {
+		v int
+		s string
+	}{
+		{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+		{50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+	} {
+		for n >= d.v {
+			sb.WriteString(d.s)
+			n -= d.v
+		}
+	}
+	if upper {
+		return strings.ToUpper(sb.String())
+	}
+	return sb.String()
+}
+
+// runLanguages counts the characters of text in each w:lang language.
+type runLanguages map[string]int
+
+// dominant returns the language with the most text, or "".
+func (l runLanguages) dominant() string {
+	best, n := "", 0
+	for lang, c := range l {
+		if c > n || c == n && lang < best {
+			best, n = lang, c
+		}
+	}
+	return best
+}
+
+// readDOCXPart opens an archive entry and parses it with parse.
+func readDOCXPart[T any](ctx context.Context, zf *zip.File, parse func(io.Reader) (T, error)) (T, error) {
+	rc, err := openZipEntry(zf)
 	if err != nil {
-		return "", fmt.Errorf("open document.xml: %w", err)
+		var zero T
+		return zero, err
 	}
 	defer rc.Close()
+	return parse(ctxReader{ctx, rc})
+}
 
-	return parseDocumentXML(io.LimitReader(rc, maxDecompressed))
+// docxParser reads one WordprocessingML part (the body, a header, the
+// footnotes) into a docBuilder. Text lives in <w:t> elements and
+// paragraphs are <w:p> elements, but tables and text boxes nest further
+// paragraphs inside: a text box sits in a run of its paragraph, so open
+// paragraphs form a stack.
+//
+// Tracked changes are resolved as the text is read. Insertions are
+// <w:ins> and <w:moveTo> around runs, deletions <w:del> and <w:moveFrom>
+// around runs whose text is in <w:delText>; whichever the chosen view
+// drops is skipped. A paragraph whose mark was inserted or deleted is
+// joined to the next one in the view where the mark is missing.
+type docxParser struct {
+	b     *docBuilder
+	opts  DOCXOptions
+	title string // text of the first "Title" paragraph
+
+	para   *docxParagraph   // innermost open paragraph, or nil
+	outer  []*docxParagraph // paragraphs interrupted by a text box
+	tables []*docxTable     // open tables, innermost last
+	inText bool
+	inPPr  bool   // in a paragraph's properties
+	inMark bool   // in <w:pPr><w:rPr>, the paragraph mark's properties
+	carry  string // text of a joined paragraph, continued by the next
+
+	langs   runLanguages
+	runLang string
+
+	// notes, when set, collects each note's paragraphs by ID, for
+	// footnotes.xml and endnotes.xml; note is the one being read.
+	notes   map[string][]string
+	note    string
+	authors map[string]string // comment authors by ID
+
+	// comments, when set, are read out at their w:commentReference.
+	comments map[string]docxNote
+
+	// styles and numbering, when the document has them, give
+	// paragraphs their heading levels and list labels.
+	styles    *docxStyles
+	numbering *docxNumbering
+
+	// footnoteRefs and endnoteRefs are the note IDs in the order the
+	// text first cites them.
+	footnoteRefs, endnoteRefs []string
 }
 
-// parseDocumentXML extracts plain text from Word's document.xml.
-// Text lives in <w:t> elements; paragraphs are <w:p> elements.
-func parseDocumentXML(r io.Reader) (string, error) {
-	decoder := xml.NewDecoder(r)
-	var paragraphs []string
-	var currentParagraph strings.Builder
-	inText := false
+type docxParagraph struct {
+	text    strings.Builder
+	style   string // <w:pStyle> ID
+	numID   string // <w:numPr> list numbering, if set on the paragraph
+	ilvl    string
+	outline int  // <w:outlineLvl> + 1, if set on the paragraph
+	joined  bool // the paragraph mark is missing from the view
+}
+
+// docxTable is a table being read. A row is spoken as its cells in
+// order, separated by commas; once a row marked as the table header has
+// been read, later cells are prefixed with their column's header.
+type docxTable struct {
+	header    []string
+	cells     []string // cells of the current row
+	cell      []string // paragraphs of the current cell
+	inCell    bool
+	headerRow bool // the current row is a header row
+}
 
+func newDOCXParser(b *docBuilder, opts DOCXOptions) *docxParser {
+	return &docxParser{b: b, opts: opts, langs: runLanguages{}}
+}
+
+// parse reads a part from r.
+func (p *docxParser) parse(r io.Reader) error {
+	decoder := xml.NewDecoder(r)
 	for {
 		tok, err := decoder.Token()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("parse xml: %w", err)
+			return fmt.Errorf("parse xml: %w", err)
 		}
 
 		switch t := tok.(type) {
 		case xml.StartElement:
-			localName := t.Name.Local
-			if localName == "t" {
-				inText = true
-			}
-			// <w:br/> and <w:cr/> are line breaks within a paragraph.
-			if localName == "br" || localName == "cr" {
-				currentParagraph.WriteString("\n")
-			}
-			// <w:tab/> is a tab.
-			if localName == "tab" {
-				currentParagraph.WriteString("\t")
+			if err := p.start(decoder, t); err != nil {
+				return fmt.Errorf("parse xml: %w", err)
 			}
 		case xml.EndElement:
-			localName := t.Name.Local
-			if localName == "t" {
-				inText = false
+			p.end(t)
+		case xml.CharData:
+			if p.inText && p.para != nil {
+				p.para.text.Write(t)
+				p.langs[p.runLang] += len(t)
 			}
-			// End of a paragraph — flush the buffer.
-			if localName == "p" {
-				text := strings.TrimRight(currentParagraph.String(), " \t")
-				paragraphs = append(paragraphs, text)
-				currentParagraph.Reset()
+		}
+	}
+
+	// Flush a paragraph left open by a truncated part, or joined to
+	// one that never came.
+	if p.para != nil {
+		p.endParagraph()
+	}
+	if p.carry != "" {
+		p.emit(p.carry)
+		p.carry = ""
+	}
+	return nil
+}
+
+func (p *docxParser) start(decoder *xml.Decoder, el xml.StartElement) error {
+	switch el.Name.Local {
+	case "Fallback":
+		// <mc:AlternateContent> offers the same content twice, e.g. a
+		// text box as DrawingML in <mc:Choice> and as VML in
+		// <mc:Fallback>. Read only the first.
+		return decoder.Skip()
+	case "p":
+		p.para = &docxParagraph{}
+		p.para.text.WriteString(p.carry)
+		p.carry = ""
+	case "pPr":
+		p.inPPr = true
+	case "rPr":
+		p.inMark = p.inPPr
+	case "ins", "moveTo", "del", "moveFrom":
+		inserted := el.Name.Local == "ins" || el.Name.Local == "moveTo"
+		if p.inPPr {
+			// <w:pPr><w:rPr><w:del/> marks the paragraph mark itself
+			// as deleted (or inserted): the paragraph runs on into the
+			// next one in the view without it. Elsewhere in the
+			// properties, such as <w:numPr><w:ins/>, they only record
+			// a formatting change.
+			if p.inMark && p.para != nil && inserted == p.opts.Original {
+				p.para.joined = true
 			}
-		case xml.CharData:
-			if inText {
-				currentParagraph.Write(t)
+			return decoder.Skip()
+		}
+		if inserted == p.opts.Original {
+			return decoder.Skip()
+		}
+	case "delText":
+		// Deleted text: only found inside a deletion, so only read when
+		// the original view keeps it.
+		p.inText = true
+	case "rPrChange", "pPrChange", "sectPrChange", "tblPrChange", "trPrChange", "tcPrChange":
+		// Formatting changes hold the old properties; the style an
+		// accepted view has is the current one.
+		if !p.opts.Original || el.Name.Local != "pPrChange" {
+			return decoder.Skip()
+		}
+		if p.para != nil {
+			p.para.style, p.para.numID, p.para.ilvl, p.para.outline = "", "", "", 0
+		}
+	case "commentReference":
+		if c, ok := p.comments[xmlAttr(el, "id")]; ok && c.text != "" {
+			p.write(commentText(c))
+		}
+	case "pStyle":
+		// <w:pStyle w:val="Heading1"/> names the paragraph style.
+		if p.para != nil {
+			p.para.style = xmlAttr(el, "val")
+		}
+	case "numId", "ilvl", "outlineLvl":
+		// <w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr>
+		// makes the paragraph a list item; <w:outlineLvl> a heading.
+		if p.para == nil || !p.inPPr {
+			break
+		}
+		switch v := xmlAttr(el, "val"); el.Name.Local {
+		case "numId":
+			p.para.numID = v
+		case "ilvl":
+			p.para.ilvl = v
+		case "outlineLvl":
+			if n, err := strconv.Atoi(v); err == nil {
+				p.para.outline = n + 1
 			}
 		}
+	case "r":
+		// <w:r> is a run of text; <w:lang w:val="de-DE"/> in its
+		// properties names its language.
+		p.runLang = ""
+	case "lang":
+		p.runLang = xmlAttr(el, "val")
+	case "t":
+		p.inText = true
+	case "br", "cr":
+		// Line breaks within a paragraph.
+		p.write("\n")
+	case "tab":
+		p.write("\t")
+	case "txbxContent":
+		// A text box: its paragraphs are read before the rest of the
+		// paragraph anchoring it.
+		p.outer = append(p.outer, p.para)
+		p.para = nil
+	case "tbl":
+		p.tables = append(p.tables, &docxTable{})
+	case "tr":
+		if t := p.table(); t != nil {
+			t.cells, t.headerRow = nil, false
+		}
+	case "tblHeader":
+		if t := p.table(); t != nil {
+			t.headerRow = xmlOnOff(el)
+		}
+	case "tc":
+		if t := p.table(); t != nil {
+			t.cell, t.inCell = nil, true
+		}
+	case "footnoteReference":
+		if !p.opts.SkipNotes {
+			p.cite(&p.footnoteRefs, xmlAttr(el, "id"), false)
+		}
+	case "endnoteReference":
+		if !p.opts.SkipNotes {
+			p.cite(&p.endnoteRefs, xmlAttr(el, "id"), true)
+		}
+	case "footnote", "endnote", "comment":
+		// A note in footnotes.xml, endnotes.xml or comments.xml. The
+		// separator lines Word keeps as special footnotes are not text.
+		if p.notes == nil {
+			break
+		}
+		if typ := xmlAttr(el, "type"); typ != "" && typ != "normal" {
+			return decoder.Skip()
+		}
+		p.note = xmlAttr(el, "id")
+		if author := xmlAttr(el, "author"); author != "" && p.authors != nil {
+			p.authors[p.note] = author
+		}
+	}
+	return nil
+}
+
+func (p *docxParser) end(el xml.EndElement) {
+	switch el.Name.Local {
+	case "t", "delText":
+		p.inText = false
+	case "pPr":
+		p.inPPr = false
+	case "rPr":
+		p.inMark = false
+	case "p":
+		if p.para != nil {
+			p.endParagraph()
+		}
+	case "txbxContent":
+		if n := len(p.outer); n > 0 {
+			p.para, p.outer = p.outer[n-1], p.outer[:n-1]
+		}
+	case "tc":
+		if t := p.table(); t != nil {
+			t.cells = append(t.cells, strings.Join(t.cell, " "))
+			t.cell, t.inCell = nil, false
+		}
+	case "tr":
+		if t := p.table(); t != nil {
+			p.emit(t.endRow())
+		}
+	case "tbl":
+		if n := len(p.tables); n > 0 {
+			p.tables = p.tables[:n-1]
+		}
+	case "footnote", "endnote", "comment":
+		p.note = ""
+	}
+}
+
+// write appends s to the open paragraph, if any.
+func (p *docxParser) write(s string) {
+	if p.para != nil {
+		p.para.text.WriteString(s)
+	}
+}
+
+// endParagraph closes the open paragraph. Only body paragraphs can be
+// the title or a heading; ones in tables, text boxes and notes are text.
+func (p *docxParser) endParagraph() {
+	para := p.para
+	p.para = nil
+	if para.joined && len(p.tables) == 0 {
+		p.carry = para.text.String()
+		return
+	}
+	text := strings.TrimRight(para.text.String(), " \t")
+
+	// A list paragraph is read with its number or bullet. The counter
+	// advances even for an empty item, as Word's does.
+	numID, ilvl := para.numID, para.ilvl
+	if numID == "" {
+		var styleIlvl string
+		numID, styleIlvl = p.styles.numbering(para.style)
+		ilvl = cmp.Or(ilvl, styleIlvl)
+	}
+	if label := p.numbering.label(numID, ilvl); label != "" && strings.TrimSpace(text) != "" {
+		text = label + " " + strings.TrimLeft(text, " \t")
+	}
+
+	if len(p.tables) > 0 || len(p.outer) > 0 || p.notes != nil {
+		p.emit(text)
+		return
+	}
+	level := p.styles.headingLevel(para.style)
+	if para.outline > 0 {
+		level = para.outline
+		if level > 9 {
+			level = 0
+		}
 	}
+	switch {
+	case p.styles.isTitle(para.style) && p.title == "":
+		p.title = text
+	case level > 0:
+		p.b.heading(level, text)
+	default:
+		p.b.paragraph(text)
+	}
+}
+
+// emit adds a paragraph of text where the parser is: to the innermost
+// open table cell, the note being read, or the document.
+func (p *docxParser) emit(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for i := len(p.tables) - 1; i >= 0; i-- {
+		if t := p.tables[i]; t.inCell {
+			t.cell = append(t.cell, text)
+			return
+		}
+	}
+	if p.notes != nil {
+		if p.note != "" {
+			p.notes[p.note] = append(p.notes[p.note], text)
+		}
+		return
+	}
+	p.b.paragraph(text)
+}
 
-	// Flush any remaining text.
-	if currentParagraph.Len() > 0 {
-		paragraphs = append(paragraphs, currentParagraph.String())
+// table returns the innermost open table, or nil.
+func (p *docxParser) table() *docxTable {
+	if n := len(p.tables); n > 0 {
+		return p.tables[n-1]
 	}
+	return nil
+}
 
-	return strings.TrimSpace(strings.Join(paragraphs, "\n")), nil
+// endRow returns the spoken form of the row just read.
+func (t *docxTable) endRow() string {
+	if t.headerRow {
+		t.header = t.cells
+	}
+	var parts []string
+	for i, c := range t.cells {
+		if c == "" {
+			continue
+		}
+		if !t.headerRow && i < len(t.header) && t.header[i] != "" {
+			c = t.header[i] + ": " + c
+		}
+		parts = append(parts, c)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cite records a reference to note id in refs and writes its marker
+// into the open paragraph. A note cited again keeps its first number.
+func (p *docxParser) cite(refs *[]string, id string, endnote bool) {
+	n := slices.Index(*refs, id) + 1
+	if n == 0 {
+		*refs = append(*refs, id)
+		n = len(*refs)
+	}
+	p.write("[" + noteNumber(endnote, n) + "]")
+}
+
+// commentText is how a comment is read at its anchor.
+func commentText(c docxNote) string {
+	if c.author != "" {
+		return " (Comment from " + c.author + ": " + c.text + ")"
+	}
+	return " (Comment: " + c.text + ")"
+}
+
+// headingStyleLevel returns N for Word's built-in "HeadingN" style IDs,
+// or 0 if styleID is not a heading.
+func headingStyleLevel(styleID string) int {
+	rest, ok := strings.CutPrefix(strings.ToLower(styleID), "heading")
+	if !ok || len(rest) != 1 || rest[0] < '1' || rest[0] > '9' {
+		return 0
+	}
+	return int(rest[0] - '0')
+}
+
+// xmlAttr returns the value of the attribute with the given local name,
+// ignoring its namespace.
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// xmlOnOff reads a WordprocessingML on/off property such as
+// <w:tblHeader/>: on unless its w:val says otherwise.
+func xmlOnOff(el xml.StartElement) bool {
+	switch xmlAttr(el, "val") {
+	case "0", "false", "off":
+		return false
+	}
+	return true
 }
@@ -0,0 +1,371 @@
+package extractor
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// docxStyles are the paragraph styles of a Word document, from
+// word/styles.xml, by style ID.
+type docxStyles struct {
+	lang   string // w:lang of the document defaults
+	styles map[string]docxStyle
+}
+
+// docxStyle is what a paragraph style says about structure. Fields are
+// left unset when the style inherits them from its basedOn style.
+type docxStyle struct {
+	name    string // lower-cased, e.g. "heading 1", even in localized Word
+	basedOn string
+	outline int    // outline level + 1; 0 if unset
+	numID   string // list numbering, if the style is a list style
+	ilvl    string
+}
+
+// parseStylesXML reads word/styles.xml.
+func parseStylesXML(r io.Reader) (*docxStyles, error) {
+	type val struct {
+		Val string `xml:"val,attr"`
+	}
+	var doc struct {
+		Lang   val `xml:"docDefaults>rPrDefault>rPr>lang"`
+		Styles []struct {
+			Type    string `xml:"type,attr"`
+			ID      string `xml:"styleId,attr"`
+			Name    val    `xml:"name"`
+			BasedOn val    `xml:"basedOn"`
+			Outline *val   `xml:"pPr>outlineLvl"`
+			NumID   val    `xml:"pPr>numPr>numId"`
+			Ilvl    val    `xml:"pPr>numPr>ilvl"`
+		} `xml:"style"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	s := &docxStyles{lang: doc.Lang.Val, styles: map[string]docxStyle{}}
+	for _, st := range doc.Styles {
+		if st.Type != "" && st.Type != "paragraph" {
+			continue
+		}
+		style := docxStyle{
+			name:    strings.ToLower(strings.TrimSpace(st.Name.Val)),
+			basedOn: st.BasedOn.Val,
+			numID:   st.NumID.Val,
+			ilvl:    st.Ilvl.Val,
+		}
+		if st.Outline != nil {
+			if n, err := strconv.Atoi(st.Outline.Val); err == nil {
+				style.outline = n + 1
+			}
+		}
+		s.styles[st.ID] = style
+	}
+	return s, nil
+}
+
+// maxStyleDepth bounds how far a basedOn chain is followed, in case a
+// broken file makes it a loop.
+const maxStyleDepth = 10
+
+// headingLevel returns the heading level of paragraphs in style id: N
+// for Word's "heading N" styles, or the outline level a style sets. It
+// returns 0 for body text, and for any style when s is nil, in which
+// case built-in style IDs ("Heading1") are recognized instead.
+func (s *docxStyles) headingLevel(id string) int {
+	if s == nil {
+		return headingStyleLevel(id)
+	}
+	for i := 0; i < maxStyleDepth && id != ""; i++ {
+		st, ok := s.styles[id]
+		if !ok {
+			return headingStyleLevel(id)
+		}
+		if rest, ok := strings.CutPrefix(st.name, "heading "); ok {
+			if n, err := strconv.Atoi(rest); err == nil && n >= 1 && n <= 9 {
+				return n
+			}
+		}
+		if st.outline > 0 {
+			// Outline level 9 is body text.
+			if st.outline > 9 {
+				return 0
+			}
+			return st.outline
+		}
+		id = st.basedOn
+	}
+	return 0
+}
+
+// isTitle reports whether style id is Word's "Title" style.
+func (s *docxStyles) isTitle(id string) bool {
+	if id == "Title" {
+		return true
+	}
+	return s != nil && s.styles[id].name == "title"
+}
+
+// numbering returns the list numbering paragraphs in style id inherit,
+// or "" if there is none.
+func (s *docxStyles) numbering(id string) (numID, ilvl string) {
+	if s == nil {
+		return "", ""
+	}
+	for i := 0; i < maxStyleDepth && id != ""; i++ {
+		st, ok := s.styles[id]
+		if !ok {
+			break
+		}
+		if st.numID != "" {
+			return st.numID, st.ilvl
+		}
+		id = st.basedOn
+	}
+	return "", ""
+}
+
+// docxNumbering is a Word document's list numbering, from
+// word/numbering.xml, and the counters of the lists read so far. A
+// paragraph's <w:numPr> names a numbering instance (numId) and a level
+// (ilvl); instances share the counters of their abstract definition
+// unless they restart a level with a startOverride.
+type docxNumbering struct {
+	abstracts map[string]*docxAbstractNum
+	nums      map[string]*docxNum
+}
+
+type docxAbstractNum struct {
+	levels  [9]*docxNumLevel
+	counts  [9]int
+	started [9]bool
+}
+
+type docxNum struct {
+	abstract string
+	levels   [9]*docxNumLevel // levels the instance overrides
+	restart  [9]int           // startOverride + 1, or 0
+}
+
+type docxNumLevel struct {
+	start  int
+	format string // numFmt: decimal, lowerRoman, bullet, ...
+	text   string // lvlText, e.g. "%1." or "%1.%2"
+}
+
+// numberingLevel maps a <w:lvl> of numbering.xml.
+type numberingLevel struct {
+	Ilvl  int `xml:"ilvl,attr"`
+	Start *struct {
+		Val int `xml:"val,attr"`
+	} `xml:"start"`
+	Format struct {
+		Val string `xml:"val,attr"`
+	} `xml:"numFmt"`
+	Text *struct {
+		Val string `xml:"val,attr"`
+	} `xml:"lvlText"`
+}
+
+func (l numberingLevel) level() *docxNumLevel {
+	// A missing start is 0, as in Word.
+	lvl := &docxNumLevel{format: l.Format.Val}
+	if l.Start != nil {
+		lvl.start = clampListStart(l.Start.Val)
+	}
+	if l.Text != nil {
+		lvl.text = l.Text.Val
+	} else {
+		lvl.text = "%" + strconv.Itoa(l.Ilvl+1) + "."
+	}
+	return lvl
+}
+
+// maxListStart is the largest start value Word accepts. Larger values
+// in a crafted file would otherwise make letter labels huge.
+const maxListStart = 32767
+
+// clampListStart bounds a w:start or w:startOverride value.
+func clampListStart(n int) int {
+	return max(0, min(n, maxListStart))
+}
+
+// parseNumberingXML reads word/numbering.xml.
+func parseNumberingXML(r io.Reader) (*docxNumbering, error) {
+	var doc struct {
+		Abstracts []struct {
+			ID     string           `xml:"abstractNumId,attr"`
+			Levels []numberingLevel `xml:"lvl"`
+		} `xml:"abstractNum"`
+		Nums []struct {
+			ID       string `xml:"numId,attr"`
+			Abstract struct {
+				Val string `xml:"val,attr"`
+			} `xml:"abstractNumId"`
+			Overrides []struct {
+				Ilvl  int `xml:"ilvl,attr"`
+				Start *struct {
+					Val int `xml:"val,attr"`
+				} `xml:"startOverride"`
+				Level *numberingLevel `xml:"lvl"`
+			} `xml:"lvlOverride"`
+		} `xml:"num"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	n := &docxNumbering{
+		abstracts: map[string]*docxAbstractNum{},
+		nums:      map[string]*docxNum{},
+	}
+	for _, a := range doc.Abstracts {
+		abs := &docxAbstractNum{}
+		for _, l := range a.Levels {
+			if l.Ilvl >= 0 && l.Ilvl < 9 {
+				abs.levels[l.Ilvl] = l.level()
+			}
+		}
+		n.abstracts[a.ID] = abs
+	}
+	for _, d := range doc.Nums {
+		num := &docxNum{abstract: d.Abstract.Val}
+		for _, o := range d.Overrides {
+			if o.Ilvl < 0 || o.Ilvl >= 9 {
+				continue
+			}
+			if o.Level != nil {
+				num.levels[o.Ilvl] = o.Level.level()
+			}
+			if o.Start != nil {
+				num.restart[o.Ilvl] = clampListStart(o.Start.Val) + 1
+			}
+		}
+		n.nums[d.ID] = num
+	}
+	return n, nil
+}
+
+// label advances the counter of a list paragraph and returns the label
+// it is read with: its number ("3.", "1.2", "b)") or "•" for any
+// bullet, whose glyph is often a private-use symbol-font character. It
+// returns "" for numId 0, which turns numbering off, and unknown lists.
+func (n *docxNumbering) label(numID, ilvl string) string {
+	if n == nil || numID == "" || numID == "0" {
+		return ""
+	}
+	num, ok := n.nums[numID]
+	if !ok {
+		return ""
+	}
+	abs, ok := n.abstracts[num.abstract]
+	if !ok {
+		return ""
+	}
+	level, _ := strconv.Atoi(ilvl)
+	if level < 0 || level >= 9 {
+		return ""
+	}
+	lvl := num.level(abs, level)
+	if lvl == nil {
+		return ""
+	}
+
+	// A startOverride restarts the level the first time the instance
+	// uses it.
+	if r := num.restart[level]; r > 0 {
+		abs.counts[level], abs.started[level] = r-2, true
+		num.restart[level] = 0
+	}
+	if !abs.started[level] {
+		abs.counts[level], abs.started[level] = lvl.start-1, true
+	}
+	abs.counts[level]++
+	// Deeper levels start over under a new item.
+	for d := level + 1; d < 9; d++ {
+		abs.started[d] = false
+	}
+
+	switch lvl.format {
+	case "bullet":
+		return "•"
+	case "none":
+		return ""
+	}
+	var b strings.Builder
+	text := lvl.text
+	for i := 0; i < len(text); i++ {
+		if text[i] == '%' && i+1 < len(text) && text[i+1] >= '1' && text[i+1] <= '9' {
+			d := int(text[i+1] - '1')
+			if l := num.level(abs, d); l != nil && d <= level {
+				count := abs.counts[d]
+				if !abs.started[d] {
+					count = l.start
+				}
+				b.WriteString(formatListNumber(l.format, count))
+			}
+			i++
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// level returns the definition of a level, as overridden by the
+// instance.
+func (num *docxNum) level(abs *docxAbstractNum, level int) *docxNumLevel {
+	if l := num.levels[level]; l != nil {
+		return l
+	}
+	return abs.levels[level]
+}
+
+// formatListNumber writes n in a numFmt. Formats without a spoken form
+// of their own (Chinese counting, ...) fall back to decimal.
+func formatListNumber(format string, n int) string {
+	switch format {
+	case "lowerLetter", "upperLetter":
+		s := listLetters(n)
+		if format == "upperLetter" {
+			s = strings.ToUpper(s)
+		}
+		return s
+	case "lowerRoman":
+		return romanNumeral(n, false)
+	case "upperRoman":
+		return romanNumeral(n, true)
+	case "decimalZero":
+		if n >= 0 && n < 10 {
+			return "0" + strconv.Itoa(n)
+		}
+	case "ordinal":
+		return strconv.Itoa(n) + ordinalSuffix(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// listLetters numbers like Word's letter lists: a ... z, then aa ... zz,
+// aaa, ...
+func listLetters(n int) string {
+	if n <= 0 {
+		return strconv.Itoa(n)
+	}
+	letter := string(rune('a' + (n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
+
+func ordinalSuffix(n int) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
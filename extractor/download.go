@@ -0,0 +1,164 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	readability "github.com/go-shiori/go-readability"
+)
+
+// maxDownload caps the size of a fetched page or document, the same as
+// the uncompressed size allowed for ZIP-based documents.
+const maxDownload = maxDecompressed
+
+// Magic numbers of the binary formats a link may point to.
+var (
+	pdfMagic  = []byte("%PDF-")
+	zipMagic  = []byte("PK\x03\x04")
+	oleMagic  = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+	epubMagic = []byte("mimetypeapplication/epub+zip")
+)
+
+// fetchDocument downloads a URL and extracts it according to what it
+// turns out to be: an HTML page goes through go-readability, and a
+// document is handed to ExtractFile. Redirects are followed; relative
+// links resolve against the final URL.
+func fetchDocument(ctx context.Context, pageURL string) (*URLResult, error) {
+	reportProgress(ctx, StageFetching, 0, 0)
+	dl, err := download(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := sniffFormat(dl.contentType, dl.data, dl.url.Path)
+	switch ext {
+	case "":
+		return nil, fmt.Errorf("unsupported content type %q", dl.contentType)
+	case ".html":
+		reportProgress(ctx, StageParsing, 0, 0)
+		article, err := readability.FromReader(bytes.NewReader(dl.data), dl.url)
+		if err != nil {
+			return nil, err
+		}
+		result := articleResult(article)
+		if result.Language == "" {
+			result.Language = metaLanguage(dl.data)
+		}
+		result.FinalURL = canonicalURL(dl.data, dl.url)
+		if result.FinalURL == "" {
+			result.FinalURL = dl.url.String()
+		}
+		return stitchPages(ctx, result, dl), nil
+	}
+
+	doc, err := ExtractFile(ctx, "download"+ext, bytes.NewReader(dl.data))
+	if err != nil {
+		return nil, err
+	}
+	title := doc.Title
+	if title == "" {
+		title = urlFilename(dl.url)
+	}
+	result := &URLResult{Title: title, Text: doc.Text(), Document: doc, Metadata: doc.Metadata}
+	result.FinalURL = dl.url.String()
+	return result, nil
+}
+
+// downloaded is a fetched page or file.
+type downloaded struct {
+	data        []byte
+	url         *url.URL // after redirects
+	contentType string
+}
+
+// download fetches rawURL, up to maxDownload bytes.
+func download(ctx context.Context, rawURL string) (*downloaded, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := fetch(ctx, rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > maxDownload {
+		return nil, fmt.Errorf("download too large (%d bytes, limit %d)", resp.ContentLength, maxDownload)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the page: %w", err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("download too large (over %d bytes)", maxDownload)
+	}
+	return &downloaded{data, resp.Request.URL, resp.Header.Get("Content-Type")}, nil
+}
+
+// sniffFormat returns the file extension whose extractor handles a
+// download: ".html" for web pages, "" if nothing does. Magic numbers
+// are trusted first since servers often send binary documents as
+// application/octet-stream, then the Content-Type, then the URL's
+// extension and content sniffing.
+func sniffFormat(contentType string, data []byte, urlPath string) string {
+	urlExt := strings.ToLower(path.Ext(urlPath))
+	switch {
+	case bytes.HasPrefix(data, pdfMagic):
+		return ".pdf"
+	case bytes.HasPrefix(data, zipMagic):
+		// An EPUB's first entry is an uncompressed "mimetype" file,
+		// whose name and content follow the 30-byte local header.
+		if (len(data) > 30 && bytes.HasPrefix(data[30:], epubMagic)) || urlExt == ".epub" {
+			return ".epub"
+		}
+		return ".docx"
+	case bytes.HasPrefix(data, oleMagic):
+		return ".doc"
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return ".html"
+	case "text/markdown", "text/x-markdown":
+		return ".md"
+	case "text/plain":
+		if urlExt == ".md" {
+			return ".md"
+		}
+		return ".txt"
+	}
+
+	sniffed := http.DetectContentType(data)
+	switch {
+	case strings.HasPrefix(sniffed, "text/html"):
+		return ".html"
+	case strings.HasPrefix(sniffed, "text/plain"):
+		if urlExt == ".md" {
+			return ".md"
+		}
+		return ".txt"
+	}
+	return ""
+}
+
+// urlFilename returns the last path element of u, for use as a title,
+// or the hostname if the path has none.
+func urlFilename(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return u.Hostname()
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
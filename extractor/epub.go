@@ -0,0 +1,199 @@
+package extractor
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ExtractEPUB reads an .epub file from an io.Reader and returns the book
+// as a Document titled from the package metadata. An .epub file is a ZIP archive whose
+// META-INF/container.xml points at an OPF package document; the OPF
+// spine lists the XHTML chapters in reading order. Canceling ctx stops
+// at the next read.
+func ExtractEPUB(ctx context.Context, r io.Reader) (*Document, error) {
+	zr, err := openZip(ctx, r, "read-aloud-*.epub")
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	opfPath, err := epubRootfile(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := epubPackage(zr, opfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Manifest hrefs are relative to the OPF file.
+	baseDir := path.Dir(opfPath)
+	manifest := make(map[string]opfItem, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		manifest[item.ID] = item
+	}
+
+	var b docBuilder
+	var total int64
+	for _, ref := range pkg.Spine {
+		item, ok := manifest[ref.IDRef]
+		if !ok || !isXHTML(item.MediaType) {
+			continue
+		}
+		zf := zr.find(resolveEPUBHref(baseDir, item.Href))
+		if zf == nil {
+			continue
+		}
+
+		// Guard against zip bombs across the whole book, not just each
+		// chapter.
+		total += int64(zf.UncompressedSize64)
+		if total > maxDecompressed {
+			return nil, fmt.Errorf("epub content too large (over %d bytes)", maxDecompressed)
+		}
+
+		// Each chapter starts a new section even if it has no heading.
+		b.breakSection()
+		if err := b.addEPUBChapter(ctx, zf); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := b.document(pkg.Title)
+	if len(doc.Sections) == 0 {
+		return nil, fmt.Errorf("no readable chapters found in epub")
+	}
+	doc.Metadata = Metadata{
+		Byline:   strings.TrimSpace(pkg.Creator),
+		SiteName: strings.TrimSpace(pkg.Publisher),
+		Excerpt:  strings.TrimSpace(pkg.Description),
+		Language: strings.TrimSpace(pkg.Language),
+	}
+	if t, ok := parseDCDate(pkg.Date); ok {
+		doc.Metadata.Published = &t
+	}
+	return doc, nil
+}
+
+// epubContainer maps META-INF/container.xml.
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// opfPackage maps the parts of the OPF package document we need.
+type opfPackage struct {
+	Title       string    `xml:"metadata>title"`
+	Creator     string    `xml:"metadata>creator"`
+	Publisher   string    `xml:"metadata>publisher"`
+	Description string    `xml:"metadata>description"`
+	Language    string    `xml:"metadata>language"`
+	Date        string    `xml:"metadata>date"`
+	Manifest    []opfItem `xml:"manifest>item"`
+	Spine       []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// opfItem is a single manifest entry.
+type opfItem struct {
+	ID        string `xml:"id,attr"`
+	Href      string `xml:"href,attr"`
+	MediaType string `xml:"media-type,attr"`
+}
+
+// epubRootfile returns the archive path of the OPF package document.
+func epubRootfile(zr *zipArchive) (string, error) {
+	zf := zr.find("META-INF/container.xml")
+	if zf == nil {
+		return "", fmt.Errorf("META-INF/container.xml not found in epub")
+	}
+	rc, err := openZipEntry(zf)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var c epubContainer
+	if err := xml.NewDecoder(rc).Decode(&c); err != nil {
+		return "", fmt.Errorf("parse container.xml: %w", err)
+	}
+	for _, rf := range c.Rootfiles {
+		if rf.MediaType == "" || rf.MediaType == "application/oebps-package+xml" {
+			return rf.FullPath, nil
+		}
+	}
+	return "", fmt.Errorf("no OPF rootfile listed in container.xml")
+}
+
+// epubPackage reads and parses the OPF package document.
+func epubPackage(zr *zipArchive, opfPath string) (*opfPackage, error) {
+	zf := zr.find(opfPath)
+	if zf == nil {
+		return nil, fmt.Errorf("%s not found in epub", opfPath)
+	}
+	rc, err := openZipEntry(zf)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var pkg opfPackage
+	if err := xml.NewDecoder(rc).Decode(&pkg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", opfPath, err)
+	}
+	return &pkg, nil
+}
+
+// addEPUBChapter adds the content of a single XHTML chapter to b. The
+// HTML parser is used rather than encoding/xml because real-world
+// e-books often contain entities and markup that strict XML rejects.
+func (b *docBuilder) addEPUBChapter(ctx context.Context, zf *zip.File) error {
+	rc, err := openZipEntry(zf)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	doc, err := html.Parse(ctxReader{ctx, rc})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("parse %s: %w", zf.Name, err)
+	}
+	b.addHTML(doc)
+	return nil
+}
+
+// resolveEPUBHref resolves a manifest href against the OPF directory,
+// dropping any fragment and undoing percent-encoding.
+func resolveEPUBHref(baseDir, href string) string {
+	if i := strings.IndexByte(href, '#'); i >= 0 {
+		href = href[:i]
+	}
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	return path.Join(baseDir, href)
+}
+
+// isXHTML reports whether a manifest media type is a readable chapter.
+func isXHTML(mediaType string) bool {
+	switch mediaType {
+	case "application/xhtml+xml", "text/html":
+		return true
+	}
+	return false
+}
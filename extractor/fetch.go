@@ -0,0 +1,143 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxRedirects is how many redirects a fetch follows before giving up.
+const maxRedirects = 10
+
+// fetchClient is the HTTP client for every outbound fetch. ValidateURL
+// alone is not enough: the fetch resolves the host again, and a
+// rebinding DNS server can answer with a private IP the second time.
+// So the dialer resolves the host itself, checks each address against
+// the egress policy and connects to exactly the address it checked, for
+// the first request and every redirect alike. Proxies are not used,
+// since the dialer would then only see the proxy's address.
+var fetchClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext:           dialChecked,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("too many redirects")
+		}
+		if err := checkURL(req.URL); err != nil {
+			return fmt.Errorf("redirect blocked: %w", err)
+		}
+		return nil
+	},
+}
+
+// fetchDialer makes the connections for dialChecked.
+var fetchDialer = &net.Dialer{
+	Timeout:   10 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+// dialChecked connects to the first address of addr's host that the
+// egress policy allows. It returns the first connection error or, if
+// every address was blocked, the policy's error for the first one.
+func dialChecked(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := resolveHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := currentPolicy.Load()
+	var firstErr error
+	for _, ip := range ips {
+		if err := policy.CheckIP(host, ip); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		conn, err := fetchDialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil || errors.Is(firstErr, ErrBlockedHost) {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// statusError is the error for a response other than 200 OK.
+type statusError struct {
+	Host string
+	Code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.Host, e.Code)
+}
+
+// isStatus reports whether err is a statusError with the given code.
+func isStatus(err error, code int) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.Code == code
+}
+
+// fetch GETs rawURL with fetchClient. Responses other than 200 OK are
+// returned as errors; otherwise the caller must close the body.
+func fetch(ctx context.Context, rawURL string) (*http.Response, error) {
+	return fetchWithHeader(ctx, rawURL, nil)
+}
+
+// fetchWithHeader is fetch with extra request headers, for APIs that
+// want an Accept or User-Agent header.
+func fetchWithHeader(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if err := checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &statusError{Host: req.URL.Host, Code: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// checkURL applies the checks of ValidateURL that need no DNS lookup:
+// the scheme and the policy's hostname rules.
+func checkURL(u *url.URL) error {
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return fmt.Errorf("unsupported scheme: %s", scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("empty hostname")
+	}
+	return currentPolicy.Load().CheckHost(host)
+}
@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -8,25 +9,44 @@ import (
 )
 
 // SupportedFileExts lists the file extensions the extractor can handle.
-var SupportedFileExts = []string{".txt", ".md", ".pdf", ".docx", ".doc"}
+var SupportedFileExts = []string{".txt", ".md", ".pdf", ".docx", ".doc", ".epub"}
 
-// ExtractFile reads an uploaded file and returns the plain text.
-// It dispatches to the correct extractor based on the file extension.
-func ExtractFile(filename string, r io.Reader) (string, error) {
+// ExtractFile reads an uploaded file and returns its content as a
+// Document. It dispatches to the correct extractor based on the file
+// extension. The document title is empty when the format carries no
+// title of its own. Metadata.Language is always set, from the file's
+// declared language and the text itself.
+func ExtractFile(ctx context.Context, filename string, r io.Reader) (*Document, error) {
+	doc, err := extractFileType(ctx, filename, r)
+	if err != nil {
+		return nil, err
+	}
+	doc.Metadata.Language = ResolveLanguage(doc.Metadata.Language, doc.Text())
+	return doc, nil
+}
+
+// extractFileType runs the extractor for filename's extension.
+func extractFileType(ctx context.Context, filename string, r io.Reader) (*Document, error) {
 	ext := strings.ToLower(filepath.Ext(filename))
+	reportProgress(ctx, StageParsing, 0, 0)
 
 	switch ext {
 	case ".txt", ".md":
-		return extractPlainText(r)
+		text, err := extractPlainText(r)
+		if err != nil {
+			return nil, err
+		}
+		return TextDocument("", text), nil
 	case ".pdf":
-		return ExtractPDF(r)
+		return ExtractPDF(ctx, r)
 	case ".docx":
-		return ExtractDOCX(r)
+		return ExtractDOCX(ctx, r)
 	case ".doc":
-		return "", fmt.Errorf(
-			".doc (legacy Word) is not supported — please save as .docx and try again")
+		return ExtractDOC(ctx, r)
+	case ".epub":
+		return ExtractEPUB(ctx, r)
 	default:
-		return "", fmt.Errorf(
+		return nil, fmt.Errorf(
 			"unsupported file type %q — supported: %s",
 			ext, strings.Join(SupportedFileExts, ", "))
 	}
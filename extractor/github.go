@@ -0,0 +1,135 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// githubAPI is the GitHub REST API.
+var githubAPI = "https://api.github.com"
+
+// githubReserved are top-level github.com paths that are not users.
+var githubReserved = map[string]bool{
+	"about": true, "apps": true, "collections": true, "explore": true,
+	"features": true, "login": true, "marketplace": true, "orgs": true,
+	"settings": true, "sponsors": true, "topics": true, "trending": true,
+}
+
+// githubSite reads a repository's README, or a Markdown file linked with
+// /blob/, as raw Markdown rather than the rendered page around it.
+var githubSite = SiteExtractor{
+	Name: "GitHub",
+	Match: func(u *url.URL) bool {
+		_, _, _, _, ok := githubTarget(u)
+		return ok
+	},
+	Extract: extractGitHub,
+}
+
+// githubTarget splits a github.com URL into the repository and, for
+// /tree/ and /blob/ links, the ref and file path. file is empty when the
+// README is wanted.
+//
+// A /blob/ URL does not say where the ref ends and the path begins, so
+// the ref is taken to be the first segment; extractGitHub tries longer
+// refs when that finds no file. /tree/ links are only matched with a
+// single-segment ref, since a longer one may as well be a directory.
+func githubTarget(u *url.URL) (owner, repo, ref, file string, ok bool) {
+	if !hostIs(u, "github.com", false) {
+		return "", "", "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || githubReserved[parts[0]] || parts[1] == "" {
+		return "", "", "", "", false
+	}
+	owner, repo = parts[0], strings.TrimSuffix(parts[1], ".git")
+	switch {
+	case len(parts) == 2:
+		return owner, repo, "", "", true
+	case parts[2] == "tree" && len(parts) == 4:
+		return owner, repo, parts[3], "", true
+	case parts[2] == "blob" && len(parts) >= 5 && isMarkdownPath(parts[len(parts)-1]):
+		return owner, repo, parts[3], strings.Join(parts[4:], "/"), true
+	}
+	return "", "", "", "", false
+}
+
+// maxGitHubRefTries bounds the refs tried for a /blob/ URL.
+const maxGitHubRefTries = 4
+
+// githubContent fetches a file, or the README when file is empty, as
+// raw Markdown. An empty ref means the default branch.
+func githubContent(ctx context.Context, owner, repo, ref, file string) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/readme", githubAPI, url.PathEscape(owner), url.PathEscape(repo))
+	if file != "" {
+		var escaped []string
+		for _, p := range strings.Split(file, "/") {
+			escaped = append(escaped, url.PathEscape(p))
+		}
+		endpoint = fmt.Sprintf("%s/repos/%s/%s/contents/%s", githubAPI,
+			url.PathEscape(owner), url.PathEscape(repo), strings.Join(escaped, "/"))
+	}
+	if ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(ref)
+	}
+
+	resp, err := fetchWithHeader(ctx, endpoint, http.Header{
+		"Accept": {"application/vnd.github.raw"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, maxAPIResponse))
+}
+
+func isMarkdownPath(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown", ".mdown":
+		return true
+	}
+	return false
+}
+
+func extractGitHub(ctx context.Context, u *url.URL) (*URLResult, error) {
+	owner, repo, ref, file, _ := githubTarget(u)
+
+	title := owner + "/" + repo
+	if file != "" {
+		title += ": " + path.Base(file)
+	}
+
+	reportProgress(ctx, StageFetching, 0, 0)
+	md, err := githubContent(ctx, owner, repo, ref, file)
+	// Refs may contain slashes: for blob/feature/x/README.md, try ref
+	// "feature/x" next. Stop after a few, as each try is an API call.
+	for tries := 1; isStatus(err, http.StatusNotFound) && tries < maxGitHubRefTries; tries++ {
+		next, rest, found := strings.Cut(file, "/")
+		if !found {
+			break
+		}
+		ref, file = ref+"/"+next, rest
+		md, err = githubContent(ctx, owner, repo, ref, file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reportProgress(ctx, StageParsing, 0, 0)
+	doc := markdownDocument(title, string(md))
+	return &URLResult{
+		Title:    title,
+		Text:     doc.Text(),
+		Document: doc,
+		Metadata: Metadata{
+			Byline:   owner,
+			SiteName: "GitHub",
+			FinalURL: u.String(),
+		},
+	}, nil
+}
@@ -0,0 +1,89 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// hackerNewsAPI is the Algolia Hacker News API, which returns a story
+// and its whole comment tree in one response.
+var hackerNewsAPI = "https://hn.algolia.com/api/v1"
+
+// hackerNewsSite reads a Hacker News story with its discussion.
+var hackerNewsSite = SiteExtractor{
+	Name: "Hacker News",
+	Match: func(u *url.URL) bool {
+		_, err := strconv.Atoi(u.Query().Get("id"))
+		return hostIs(u, "news.ycombinator.com", false) && u.Path == "/item" && err == nil
+	},
+	Extract: extractHackerNews,
+}
+
+// hnItem is a story or comment from the Algolia API. Text is HTML.
+type hnItem struct {
+	Title     string   `json:"title"`
+	Author    string   `json:"author"`
+	Text      string   `json:"text"`
+	URL       string   `json:"url"`
+	CreatedAt int64    `json:"created_at_i"`
+	Children  []hnItem `json:"children"`
+}
+
+func (it *hnItem) comments() []threadComment {
+	comments := make([]threadComment, 0, len(it.Children))
+	for i := range it.Children {
+		c := &it.Children[i]
+		comments = append(comments, threadComment{
+			author:     c.Author,
+			paragraphs: htmlParagraphs(c.Text),
+			replies:    c.comments(),
+		})
+	}
+	return comments
+}
+
+func extractHackerNews(ctx context.Context, u *url.URL) (*URLResult, error) {
+	id := u.Query().Get("id")
+	var item hnItem
+	if err := fetchJSON(ctx, fmt.Sprintf("%s/items/%s", hackerNewsAPI, url.PathEscape(id)), nil, &item); err != nil {
+		return nil, err
+	}
+
+	title := item.Title
+	if title == "" {
+		// A link to a comment rather than a story.
+		title = "Comment by " + item.Author
+	}
+	var b docBuilder
+	if item.Title == "" {
+		b.addThread([]threadComment{{author: item.Author, paragraphs: htmlParagraphs(item.Text), replies: item.comments()}})
+	} else {
+		if item.URL != "" {
+			if link, err := url.Parse(item.URL); err == nil && link.Host != "" {
+				b.paragraph("Link to " + link.Hostname() + ".")
+			}
+		}
+		for _, p := range htmlParagraphs(item.Text) {
+			b.paragraph(p)
+		}
+		if len(item.Children) > 0 {
+			b.heading(2, "Comments")
+			b.addThread(item.comments())
+		}
+	}
+
+	doc := b.document(title)
+	m := Metadata{
+		Byline:   item.Author,
+		SiteName: "Hacker News",
+		FinalURL: u.String(),
+	}
+	if item.CreatedAt > 0 {
+		t := time.Unix(item.CreatedAt, 0).UTC()
+		m.Published = &t
+	}
+	return &URLResult{Title: title, Text: doc.Text(), Document: doc, Metadata: m}, nil
+}
@@ -0,0 +1,82 @@
+package extractor
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockElements start a new paragraph when walking HTML for text.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Li: true, atom.Blockquote: true,
+	atom.Pre: true, atom.Tr: true, atom.Dt: true, atom.Dd: true,
+	atom.Figcaption: true, atom.Header: true, atom.Footer: true,
+	atom.Aside: true, atom.Table: true, atom.Ul: true, atom.Ol: true,
+}
+
+// skippedElements never contribute readable text.
+var skippedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true,
+	atom.Noscript: true, atom.Template: true, atom.Svg: true,
+}
+
+// headingLevels maps HTML heading elements to their depth.
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// addHTML walks an HTML tree and adds its text to b, split into
+// paragraphs at block-level elements. Headings become section headings
+// (or paragraphs, past maxSectionLevel). Whitespace inside a paragraph
+// is collapsed to single spaces.
+func (b *docBuilder) addHTML(n *html.Node) {
+	var current strings.Builder
+	level := 0
+
+	flush := func() {
+		text := strings.Join(strings.Fields(current.String()), " ")
+		current.Reset()
+		if level > 0 {
+			b.heading(level, text)
+		} else {
+			b.paragraph(text)
+		}
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			current.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if skippedElements[n.DataAtom] {
+				return
+			}
+			switch n.DataAtom {
+			case atom.Br:
+				current.WriteString(" ")
+				return
+			case atom.Td, atom.Th:
+				current.WriteString(" ")
+			}
+			if blockElements[n.DataAtom] {
+				flush()
+				if l, ok := headingLevels[n.DataAtom]; ok {
+					level = l
+					defer func() { flush(); level = 0 }()
+				} else {
+					defer flush()
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	flush()
+}
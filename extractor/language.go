@@ -0,0 +1,69 @@
+package extractor
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"read-aloud/langdetect"
+)
+
+// UndeterminedLanguage is the BCP-47 tag for content whose language is
+// unknown.
+const UndeterminedLanguage = "und"
+
+// ResolveLanguage returns the BCP-47 tag for text, given the language
+// its source declares (an HTML lang attribute, a DOCX w:lang, ...),
+// which may be empty. A declared language is kept, canonicalized, unless
+// the text is confidently detected as a different one: sites often
+// leave a template's lang="en" on pages in other languages. A declared
+// region or script ("en-GB", "zh-Hant") is kept whenever the detected
+// language agrees.
+func ResolveLanguage(declared, text string) string {
+	tag, ok := parseLanguage(declared)
+	detected, confident := langdetect.Detect(text)
+	switch {
+	case ok && (!confident || sameLanguage(tag, detected)):
+		return tag.String()
+	case detected != "":
+		return detected
+	case ok:
+		return tag.String()
+	}
+	return UndeterminedLanguage
+}
+
+// parseLanguage parses a declared language tag, accepting "_" for "-"
+// and replacing deprecated codes ("iw" becomes "he"). Tags that name no
+// particular language ("und", "x-default", "simple") are rejected.
+func parseLanguage(s string) (language.Tag, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "_", "-")
+	tag, err := language.Parse(s)
+	if err != nil {
+		return language.Und, false
+	}
+	if _, conf := tag.Base(); conf != language.Exact {
+		return language.Und, false
+	}
+	return tag, true
+}
+
+// sameLanguage reports whether tag is the detected language, counting
+// languages the detector cannot reliably tell apart as the same.
+func sameLanguage(tag language.Tag, detected string) bool {
+	base, _ := tag.Base()
+	a, b := base.String(), detected
+	if a == b {
+		return true
+	}
+	return closeLanguages[a] != "" && closeLanguages[a] == closeLanguages[b]
+}
+
+// closeLanguages groups languages close enough that a short text can
+// be mistaken for its neighbour.
+var closeLanguages = map[string]string{
+	"no": "nordic", "nb": "nordic", "nn": "nordic", "da": "nordic",
+	"sr": "bcs", "hr": "bcs", "bs": "bcs",
+	"cs": "czechoslovak", "sk": "czechoslovak",
+	"id": "malay", "ms": "malay",
+}
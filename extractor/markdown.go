@@ -0,0 +1,119 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Markdown syntax that has no spoken form.
+var (
+	mdHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdListItem  = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+	mdRule      = regexp.MustCompile(`^\s*(?:[-*_]\s*){3,}$`)
+	mdTableRule = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdImage     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink      = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdRefLink   = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+	mdEmphasis  = regexp.MustCompile("(\\*\\*|\\*|~~|`)([^*~`]+)(\\*\\*|\\*|~~|`)")
+	mdUnderline = regexp.MustCompile(`(^|\W)__?([^_]+?)__?(\W|$)`) // not inside snake_case
+	mdHTMLTag   = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	mdLinkDef   = regexp.MustCompile(`^\s*\[[^\]]+\]:\s+\S+`)
+)
+
+// markdownDocument builds a Document from Markdown, as found in READMEs
+// and Reddit posts. Headings start sections; code blocks, images, link
+// targets and formatting characters are dropped since none of them
+// read well aloud.
+func markdownDocument(title, md string) *Document {
+	var b docBuilder
+	for _, block := range markdownBlocks(md) {
+		if block.level > 0 {
+			b.heading(block.level, block.text)
+		} else {
+			b.paragraph(block.text)
+		}
+	}
+	return b.document(title)
+}
+
+// markdownParagraphs returns the paragraphs of a Markdown snippet, with
+// any headings kept as ordinary paragraphs.
+func markdownParagraphs(md string) []string {
+	var paragraphs []string
+	for _, block := range markdownBlocks(md) {
+		paragraphs = append(paragraphs, block.text)
+	}
+	return paragraphs
+}
+
+// mdBlock is a heading (level > 0) or paragraph of Markdown text.
+type mdBlock struct {
+	level int
+	text  string
+}
+
+func markdownBlocks(md string) []mdBlock {
+	var blocks []mdBlock
+	var para []string
+	flush := func() {
+		text := normalizeSpace(strings.Join(para, " "))
+		para = para[:0]
+		if text != "" {
+			blocks = append(blocks, mdBlock{text: text})
+		}
+	}
+
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			inCode = !inCode
+			continue
+		}
+		switch {
+		case inCode, mdRule.MatchString(line), mdLinkDef.MatchString(line),
+			strings.Contains(trimmed, "-") && mdTableRule.MatchString(line):
+			continue
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			// Indented code, unless it continues a list item.
+			if len(para) == 0 {
+				continue
+			}
+			para = append(para, markdownInline(trimmed))
+		case mdHeading.MatchString(trimmed):
+			flush()
+			m := mdHeading.FindStringSubmatch(trimmed)
+			if text := markdownInline(m[2]); text != "" {
+				blocks = append(blocks, mdBlock{level: len(m[1]), text: text})
+			}
+		case mdListItem.MatchString(line):
+			flush()
+			para = append(para, markdownInline(mdListItem.ReplaceAllString(line, "")))
+		default:
+			text := strings.TrimLeft(trimmed, "> ")
+			if strings.HasPrefix(text, "|") {
+				// Read a table row's cells as a list.
+				text = strings.Join(strings.FieldsFunc(text, func(r rune) bool { return r == '|' }), ", ")
+			}
+			para = append(para, markdownInline(text))
+		}
+	}
+	flush()
+	return blocks
+}
+
+// markdownInline strips inline Markdown and HTML from a line of text.
+func markdownInline(s string) string {
+	s = mdImage.ReplaceAllString(s, "")
+	s = mdLink.ReplaceAllString(s, "$1")
+	s = mdRefLink.ReplaceAllString(s, "$1")
+	s = mdHTMLTag.ReplaceAllString(s, "")
+	for i := 0; i < 3 && mdEmphasis.MatchString(s); i++ {
+		s = mdEmphasis.ReplaceAllString(s, "$2")
+	}
+	s = mdUnderline.ReplaceAllString(s, "$1$2$3")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,180 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mastodonBase is where a Mastodon instance's API is reached; {host} is
+// replaced by the instance's host.
+var mastodonBase = "https://{host}"
+
+// mastodonPathPattern matches status URLs on any instance, e.g.
+// /@user/123, /@user@other.host/123 or /users/user/statuses/123, and
+// captures the status ID.
+var mastodonPathPattern = regexp.MustCompile(`^/(?:@[\w.-]+(?:@[\w.-]+)?|users/[\w.-]+/statuses)/(\d+)/?$`)
+
+// mastodonSite reads Mastodon posts, and the author's thread around
+// them, through the instance's public API.
+var mastodonSite = SiteExtractor{
+	Name: "Mastodon",
+	Match: func(u *url.URL) bool {
+		return mastodonPathPattern.MatchString(u.Path)
+	},
+	Extract: extractMastodon,
+}
+
+// mastodonStatus maps the parts of a Mastodon status we need. Content
+// is HTML.
+type mastodonStatus struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Content     string    `json:"content"`
+	SpoilerText string    `json:"spoiler_text"`
+	Language    string    `json:"language"`
+	CreatedAt   time.Time `json:"created_at"`
+	InReplyToID string    `json:"in_reply_to_id"`
+	Account     struct {
+		ID          string `json:"id"`
+		Acct        string `json:"acct"`
+		DisplayName string `json:"display_name"`
+	} `json:"account"`
+	MediaAttachments []struct {
+		Type        string `json:"type"`
+		Description string `json:"description"`
+	} `json:"media_attachments"`
+	Reblog *mastodonStatus `json:"reblog"`
+	Quote  *struct {
+		State        string          `json:"state"`
+		QuotedStatus *mastodonStatus `json:"quoted_status"`
+	} `json:"quote"`
+}
+
+// mastodonContext maps the statuses before and after one in its
+// conversation, each oldest first.
+type mastodonContext struct {
+	Ancestors   []mastodonStatus `json:"ancestors"`
+	Descendants []mastodonStatus `json:"descendants"`
+}
+
+// handle returns the author's full handle, user@instance.
+func (s *mastodonStatus) handle(host string) string {
+	if strings.Contains(s.Account.Acct, "@") {
+		return s.Account.Acct
+	}
+	return s.Account.Acct + "@" + host
+}
+
+// paragraphs returns the post's text after its content warning,
+// followed by the descriptions of its media and any quoted post.
+func (s *mastodonStatus) paragraphs(host string) []string {
+	var paragraphs []string
+	if cw := strings.TrimSpace(s.SpoilerText); cw != "" {
+		paragraphs = append(paragraphs, "Content warning: "+cw+".")
+	}
+	paragraphs = append(paragraphs, htmlParagraphs(s.Content)...)
+	for _, m := range s.MediaAttachments {
+		if desc := strings.TrimSpace(m.Description); desc != "" {
+			kind := "Image"
+			if m.Type == "video" || m.Type == "gifv" {
+				kind = "Video"
+			} else if m.Type == "audio" {
+				kind = "Audio"
+			}
+			paragraphs = append(paragraphs, fmt.Sprintf("%s: %s", kind, desc))
+		}
+	}
+	if q := s.Quote; q != nil && q.State == "accepted" && q.QuotedStatus != nil {
+		qs := q.QuotedStatus
+		paragraphs = append(paragraphs, quotedPost(qs.Account.DisplayName, qs.handle(host), qs.paragraphs(host))...)
+	}
+	return paragraphs
+}
+
+func extractMastodon(ctx context.Context, u *url.URL) (*URLResult, error) {
+	id := mastodonPathPattern.FindStringSubmatch(u.Path)[1]
+	api := strings.ReplaceAll(mastodonBase, "{host}", u.Host) + "/api/v1/statuses/" + id
+
+	var status mastodonStatus
+	if err := fetchJSON(ctx, api, nil, &status); err != nil {
+		if errors.Is(err, ErrBlockedHost) || ctx.Err() != nil {
+			return nil, err
+		}
+		// Any site can have a path like /@user/123; without a
+		// Mastodon API behind it, read it as a web page.
+		return nil, errNotThisSite
+	}
+	if status.Reblog != nil {
+		status = *status.Reblog
+	}
+	api = strings.ReplaceAll(mastodonBase, "{host}", u.Host) + "/api/v1/statuses/" + url.PathEscape(status.ID)
+
+	// The thread is the author's own replies around the post. Without
+	// its context the post is read on its own.
+	thread := []mastodonStatus{status}
+	var conv mastodonContext
+	if fetchJSON(ctx, api+"/context", nil, &conv) == nil {
+		thread = mastodonThread(status, conv)
+	}
+
+	host := u.Hostname()
+	posts := make([][]string, len(thread))
+	for i := range thread {
+		posts[i] = thread[i].paragraphs(host)
+	}
+	root := thread[0]
+	handle := root.handle(host)
+	m := Metadata{
+		Byline:   strings.TrimSpace(fmt.Sprintf("%s (@%s)", root.Account.DisplayName, handle)),
+		SiteName: "Mastodon",
+		Language: root.Language,
+		FinalURL: root.URL,
+	}
+	if !root.CreatedAt.IsZero() {
+		t := root.CreatedAt.UTC()
+		m.Published = &t
+	}
+	return postsResult(handle, posts, m), nil
+}
+
+// mastodonThread returns the chain of self-replies status belongs to,
+// oldest first.
+func mastodonThread(status mastodonStatus, conv mastodonContext) []mastodonStatus {
+	author := status.Account.ID
+	byID := make(map[string]mastodonStatus, len(conv.Ancestors))
+	for _, s := range conv.Ancestors {
+		byID[s.ID] = s
+	}
+	var ancestors []mastodonStatus
+	for cur := status; cur.InReplyToID != "" && len(ancestors) < maxThreadPosts; {
+		parent, ok := byID[cur.InReplyToID]
+		if !ok || parent.Account.ID != author {
+			break
+		}
+		ancestors = append(ancestors, parent)
+		cur = parent
+	}
+
+	thread := make([]mastodonStatus, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		thread = append(thread, ancestors[i])
+	}
+	thread = append(thread, status)
+
+	inThread := map[string]bool{status.ID: true}
+	for _, s := range conv.Descendants {
+		if len(thread) >= maxThreadPosts {
+			break
+		}
+		if s.Account.ID == author && inThread[s.InReplyToID] {
+			inThread[s.ID] = true
+			thread = append(thread, s)
+		}
+	}
+	return thread
+}
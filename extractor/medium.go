@@ -0,0 +1,73 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	readability "github.com/go-shiori/go-readability"
+)
+
+// mediumBase is where Medium pages are fetched from; {host} is replaced
+// by the article's host.
+var mediumBase = "https://{host}"
+
+// mediumSite reads Medium articles. Medium has no public article API, so
+// the page goes through go-readability and the clap, share and follow
+// prompts it leaves in are dropped.
+var mediumSite = SiteExtractor{
+	Name: "Medium",
+	Match: func(u *url.URL) bool {
+		return hostIs(u, "medium.com", true) && strings.Trim(u.Path, "/") != ""
+	},
+	Extract: extractMedium,
+}
+
+// mediumChrome matches paragraphs of Medium's interface rather than the
+// article: buttons, counts and reading-time labels.
+var mediumChrome = regexp.MustCompile(`(?i)^(follow|following|listen|share|sign up|sign in|get started|open in app|member-only story|--|·|\d+(\.\d+)?k?|\d+ min read|published in .*)$`)
+
+func extractMedium(ctx context.Context, u *url.URL) (*URLResult, error) {
+	page := *u
+	page.RawQuery = ""
+	page.Fragment = ""
+	base, err := url.Parse(strings.ReplaceAll(mediumBase, "{host}", u.Host))
+	if err != nil {
+		return nil, err
+	}
+	page.Scheme, page.Host = base.Scheme, base.Host
+
+	reportProgress(ctx, StageFetching, 0, 0)
+	dl, err := download(ctx, page.String())
+	if err != nil {
+		return nil, err
+	}
+	reportProgress(ctx, StageParsing, 0, 0)
+	article, err := readability.FromReader(bytes.NewReader(dl.data), u)
+	if err != nil {
+		return nil, err
+	}
+	result := articleResult(article)
+
+	var b docBuilder
+	for _, s := range result.Document.Sections {
+		if !mediumChrome.MatchString(s.Heading) {
+			b.heading(s.Level, s.Heading)
+		}
+		for _, p := range s.Paragraphs {
+			if !mediumChrome.MatchString(p) {
+				b.paragraph(p)
+			}
+		}
+	}
+	result.Document = b.document(result.Title)
+	result.Text = result.Document.Text()
+	result.SiteName = "Medium"
+	result.FinalURL = canonicalURL(dl.data, u)
+	if result.FinalURL == "" {
+		result.FinalURL = u.String()
+	}
+	return result, nil
+}
@@ -0,0 +1,288 @@
+package extractor
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Metadata describes who wrote a document and where it came from. Every
+// field is optional; extractors fill what their format provides.
+type Metadata struct {
+	Byline    string     `json:"byline,omitempty"`
+	SiteName  string     `json:"siteName,omitempty"`
+	Excerpt   string     `json:"excerpt,omitempty"`
+	Language  string     `json:"language,omitempty"`
+	Published *time.Time `json:"published,omitempty"`
+	Image     string     `json:"image,omitempty"`
+	FinalURL  string     `json:"finalUrl,omitempty"` // canonical URL, after redirects
+}
+
+// pdfMetadata reads the document information dictionary and the
+// catalog's /Lang entry. Lookups are guarded against library panics, so
+// a broken Info dictionary only loses the metadata it would have held.
+func pdfMetadata(reader *pdf.Reader) Metadata {
+	var m Metadata
+	pdfGuard(func() {
+		info := reader.Trailer().Key("Info")
+		m.Byline = strings.TrimSpace(info.Key("Author").Text())
+		m.Excerpt = strings.TrimSpace(info.Key("Subject").Text())
+		if t, ok := parsePDFDate(info.Key("CreationDate").Text()); ok {
+			m.Published = &t
+		}
+	})
+	pdfGuard(func() {
+		m.Language = strings.TrimSpace(reader.Trailer().Key("Root").Key("Lang").Text())
+	})
+	return m
+}
+
+// parsePDFDate parses a PDF date string, D:YYYYMMDDHHmmSSOHH'mm'. Every
+// part after the year is optional.
+func parsePDFDate(s string) (time.Time, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	s = strings.ReplaceAll(s, "'", "")
+	digits := len(s)
+	for i, c := range s {
+		if c < '0' || c > '9' {
+			digits = i
+			break
+		}
+	}
+	if digits < 4 {
+		return time.Time{}, false
+	}
+	layout := "20060102150405"[:min(digits, 14)]
+	zone := s[digits:]
+	switch {
+	case zone == "" || zone[0] == 'Z':
+		zone = ""
+	case len(zone) == 5: // +HHmm
+		layout += "-0700"
+	case len(zone) == 3: // +HH
+		layout += "-07"
+	default:
+		zone = ""
+	}
+	t, err := time.Parse(layout, s[:min(digits, 14)]+zone)
+	return t, err == nil
+}
+
+// coreProperties maps the parts of docProps/core.xml we need.
+type coreProperties struct {
+	Title       string `xml:"title"`
+	Subject     string `xml:"subject"`
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+	Language    string `xml:"language"`
+	Created     string `xml:"created"`
+}
+
+// parseCoreXML reads a DOCX's document properties. The title is returned
+// separately since it belongs on the Document itself.
+func parseCoreXML(r io.Reader) (title string, m Metadata, err error) {
+	var core coreProperties
+	if err := xml.NewDecoder(r).Decode(&core); err != nil {
+		return "", Metadata{}, err
+	}
+	m = Metadata{
+		Byline:   strings.TrimSpace(core.Creator),
+		Excerpt:  strings.TrimSpace(core.Description),
+		Language: strings.TrimSpace(core.Language),
+	}
+	if m.Excerpt == "" {
+		m.Excerpt = strings.TrimSpace(core.Subject)
+	}
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(core.Created)); err == nil {
+		m.Published = &t
+	}
+	return strings.TrimSpace(core.Title), m, nil
+}
+
+// parseSummaryInformation reads the title, author, subject and date of
+// a .doc file from its SummaryInformation property set. Strings are in
+// the code page the set declares.
+func parseSummaryInformation(data []byte) (title string, m Metadata) {
+	// The stream header is followed by the format ID and offset of the
+	// first section, which lists its properties by ID and offset.
+	if len(data) < 48 {
+		return "", Metadata{}
+	}
+	sec := byteRange(data, int(le.Uint32(data[44:])), 8)
+	if sec == nil {
+		return "", Metadata{}
+	}
+	sec = byteRange(data, int(le.Uint32(data[44:])), int(le.Uint32(sec)))
+	if len(sec) < 8 {
+		return "", Metadata{}
+	}
+	props := map[uint32][]byte{}
+	for i := 0; i < int(le.Uint32(sec[4:])) && 16+8*i <= len(sec); i++ {
+		off := int(le.Uint32(sec[12+8*i:]))
+		if off+4 <= len(sec) {
+			props[le.Uint32(sec[8+8*i:])] = sec[off:]
+		}
+	}
+
+	var codePage uint16 = 1252
+	if v := props[1]; len(v) >= 6 && le.Uint16(v) == 2 { // VT_I2
+		codePage = le.Uint16(v[4:])
+	}
+	str := func(id uint32) string {
+		v := props[id]
+		if len(v) < 8 || le.Uint16(v) != 0x1E { // VT_LPSTR
+			return ""
+		}
+		n := int(le.Uint32(v[4:]))
+		if codePage == 1200 {
+			n *= 2
+		}
+		b := byteRange(v, 8, n)
+		var s string
+		switch {
+		case b == nil:
+			return ""
+		case codePage == 1200:
+			s = decodeUTF16(b)
+		case codePage == 65001:
+			s = string(b)
+		default:
+			cm := charmap.Windows1252
+			if c, ok := windowsCodePages[codePage]; ok {
+				cm = c
+			}
+			s, _ = cm.NewDecoder().String(string(b))
+		}
+		return strings.TrimSpace(strings.TrimRight(s, "\x00"))
+	}
+	m = Metadata{
+		Byline:  str(4),
+		Excerpt: cmp.Or(str(6), str(3)),
+	}
+	if v := props[12]; len(v) >= 12 && le.Uint16(v) == 0x40 { // VT_FILETIME
+		// 100-nanosecond intervals since 1601.
+		if ft := int64(le.Uint64(v[4:])); ft > 116444736000000000 {
+			t := time.Unix(0, (ft-116444736000000000)*100).UTC()
+			m.Published = &t
+		}
+	}
+	return str(2), m
+}
+
+// windowsCodePages are the ANSI code pages a property set may use.
+var windowsCodePages = map[uint16]*charmap.Charmap{
+	1250: charmap.Windows1250,
+	1251: charmap.Windows1251,
+	1252: charmap.Windows1252,
+	1253: charmap.Windows1253,
+	1254: charmap.Windows1254,
+	1255: charmap.Windows1255,
+	1256: charmap.Windows1256,
+	1257: charmap.Windows1257,
+	1258: charmap.Windows1258,
+}
+
+// parseDCDate parses a Dublin Core date, which may be a full timestamp
+// or just a year, year-month or date.
+func parseDCDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339, "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// canonicalURL returns the <link rel="canonical"> of an HTML page,
+// resolved against base, or "" if it has none. Only the head is read.
+func canonicalURL(data []byte, base *url.URL) string {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				return ""
+			case atom.Link:
+				var rel, href string
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					switch string(key) {
+					case "rel":
+						rel = strings.ToLower(string(val))
+					case "href":
+						href = string(val)
+					}
+				}
+				if rel == "canonical" && href != "" {
+					u, err := base.Parse(href)
+					if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+						return u.String()
+					}
+				}
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Head {
+				return ""
+			}
+		}
+	}
+}
+
+// metaLanguage returns the language an HTML page declares in its head
+// other than by <html lang>: <meta http-equiv="content-language"> or,
+// failing that, og:locale ("en_US"). It returns "" if there is neither.
+func metaLanguage(data []byte) string {
+	var contentLanguage, locale string
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return cmp.Or(contentLanguage, locale)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				return cmp.Or(contentLanguage, locale)
+			case atom.Meta:
+				var key, content string
+				for hasAttr {
+					var k, v []byte
+					k, v, hasAttr = z.TagAttr()
+					switch string(k) {
+					case "http-equiv", "property":
+						key = strings.ToLower(string(v))
+					case "content":
+						content = strings.TrimSpace(string(v))
+					}
+				}
+				switch key {
+				case "content-language":
+					// May list several; the first is the main one.
+					contentLanguage, _, _ = strings.Cut(content, ",")
+					contentLanguage = strings.TrimSpace(contentLanguage)
+				case "og:locale":
+					locale = content
+				}
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Head {
+				return cmp.Or(contentLanguage, locale)
+			}
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package extractor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// oleFile is an OLE2 compound file (Microsoft's "structured storage",
+// the container of .doc files) held in memory: a small FAT file system
+// of fixed-size sectors, with a directory of named streams. Streams
+// smaller than the mini stream cutoff live in 64-byte mini sectors
+// inside the root entry's stream.
+type oleFile struct {
+	data       []byte
+	sectorSize int
+	miniSize   int
+	miniCutoff uint64
+	fat        []uint32
+	miniFAT    []uint32
+	entries    []oleEntry
+	miniStream []byte
+	maxSectors int
+}
+
+// oleEntry is a directory entry: a storage (folder) or stream.
+type oleEntry struct {
+	name               string
+	kind               byte // oleStorage, oleStream or oleRoot
+	left, right, child uint32
+	start              uint32
+	size               uint64
+}
+
+// Directory entry types and special sector numbers.
+const (
+	oleStorage = 1
+	oleStream  = 2
+	oleRoot    = 5
+
+	oleNoStream   = 0xFFFFFFFF
+	oleEndOfChain = 0xFFFFFFFE
+	oleFreeSect   = 0xFFFFFFFF
+)
+
+// le reads the little-endian integers of compound files and the
+// formats stored in them.
+var le = binary.LittleEndian
+
+var errOLECorrupt = errors.New("corrupt compound file")
+
+// parseOLE reads the header, FAT and directory of a compound file.
+func parseOLE(data []byte) (*oleFile, error) {
+	if len(data) < 512 || string(data[:8]) != string(oleMagic) {
+		return nil, errors.New("not an OLE2 compound file")
+	}
+	shift := le.Uint16(data[0x1E:])
+	miniShift := le.Uint16(data[0x20:])
+	if shift != 9 && shift != 12 || miniShift != 6 {
+		return nil, errOLECorrupt
+	}
+	f := &oleFile{
+		data:       data,
+		sectorSize: 1 << shift,
+		miniSize:   1 << miniShift,
+		miniCutoff: uint64(le.Uint32(data[0x38:])),
+	}
+	f.maxSectors = len(data)/f.sectorSize + 1
+
+	// The FAT's own sectors are listed in the DIFAT: 109 entries in the
+	// header, then chained DIFAT sectors.
+	numFAT := int(le.Uint32(data[0x2C:]))
+	if numFAT > f.maxSectors {
+		return nil, errOLECorrupt
+	}
+	fatSectors := make([]uint32, 0, numFAT)
+	for i := 0; i < 109 && len(fatSectors) < numFAT; i++ {
+		fatSectors = append(fatSectors, le.Uint32(data[0x4C+4*i:]))
+	}
+	difat := le.Uint32(data[0x44:])
+	for hops := 0; len(fatSectors) < numFAT; hops++ {
+		sec, err := f.sector(difat)
+		if err != nil || hops > f.maxSectors {
+			return nil, errOLECorrupt
+		}
+		perSector := f.sectorSize/4 - 1
+		for i := 0; i < perSector && len(fatSectors) < numFAT; i++ {
+			fatSectors = append(fatSectors, le.Uint32(sec[4*i:]))
+		}
+		difat = le.Uint32(sec[4*perSector:])
+	}
+	for _, s := range fatSectors {
+		sec, err := f.sector(s)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(sec); i += 4 {
+			f.fat = append(f.fat, le.Uint32(sec[i:]))
+		}
+	}
+
+	dir, err := f.chain(le.Uint32(data[0x30:]), f.fat, f.sector, -1)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+128 <= len(dir); i += 128 {
+		e := dir[i : i+128]
+		nameLen := int(le.Uint16(e[64:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+		f.entries = append(f.entries, oleEntry{
+			name:  strings.TrimRight(decodeUTF16(e[:nameLen]), "\x00"),
+			kind:  e[66],
+			left:  le.Uint32(e[68:]),
+			right: le.Uint32(e[72:]),
+			child: le.Uint32(e[76:]),
+			start: le.Uint32(e[116:]),
+			size:  le.Uint64(e[120:]),
+		})
+	}
+	if len(f.entries) == 0 || f.entries[0].kind != oleRoot {
+		return nil, errOLECorrupt
+	}
+	if le.Uint16(data[0x1A:]) == 3 {
+		// Version 3 files may leave garbage in the high half.
+		for i := range f.entries {
+			f.entries[i].size &= 0xFFFFFFFF
+		}
+	}
+
+	// The mini FAT, and the mini stream it indexes, which is the root
+	// entry's stream.
+	if start := le.Uint32(data[0x3C:]); start != oleEndOfChain && start != oleFreeSect {
+		miniFAT, err := f.chain(start, f.fat, f.sector, -1)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(miniFAT); i += 4 {
+			f.miniFAT = append(f.miniFAT, le.Uint32(miniFAT[i:]))
+		}
+		root := f.entries[0]
+		if f.miniStream, err = f.chain(root.start, f.fat, f.sector, int64(root.size)); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// stream returns the contents of the stream called name directly in
+// the root storage. Streams in sub-storages, such as the documents
+// embedded in a Word file's ObjectPool, are not looked at.
+func (f *oleFile) stream(ctx context.Context, name string) ([]byte, error) {
+	e, ok, err := f.find(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || e.kind != oleStream {
+		return nil, fmt.Errorf("%s stream not found", name)
+	}
+	if e.size > maxDecompressed {
+		return nil, fmt.Errorf("%s stream too large (%d bytes)", name, e.size)
+	}
+	if e.size < f.miniCutoff {
+		return f.chain(e.start, f.miniFAT, f.miniSector, int64(e.size))
+	}
+	return f.chain(e.start, f.fat, f.sector, int64(e.size))
+}
+
+// find looks name up among the children of the root storage, which
+// form a red-black tree of siblings. Names compare case-insensitively
+// in the file format, but the streams Word writes always have the same
+// case. The tree is walked without recursion and visits each entry at
+// most once, since a crafted file may link entries into cycles.
+func (f *oleFile) find(ctx context.Context, name string) (oleEntry, bool, error) {
+	visited := make([]bool, len(f.entries))
+	stack := []uint32{f.entries[0].child}
+	for len(stack) > 0 {
+		if err := ctx.Err(); err != nil {
+			return oleEntry{}, false, err
+		}
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == oleNoStream || int(id) >= len(f.entries) || visited[id] {
+			continue
+		}
+		visited[id] = true
+		e := f.entries[id]
+		if e.name == name {
+			return e, true, nil
+		}
+		stack = append(stack, e.right, e.left)
+	}
+	return oleEntry{}, false, nil
+}
+
+// sector returns regular sector n.
+func (f *oleFile) sector(n uint32) ([]byte, error) {
+	off := (int64(n) + 1) * int64(f.sectorSize)
+	if n >= oleEndOfChain-3 || off+int64(f.sectorSize) > int64(len(f.data)) {
+		return nil, errOLECorrupt
+	}
+	return f.data[off : off+int64(f.sectorSize)], nil
+}
+
+// miniSector returns mini sector n of the mini stream.
+func (f *oleFile) miniSector(n uint32) ([]byte, error) {
+	off := int64(n) * int64(f.miniSize)
+	if off+int64(f.miniSize) > int64(len(f.miniStream)) {
+		return nil, errOLECorrupt
+	}
+	return f.miniStream[off : off+int64(f.miniSize)], nil
+}
+
+// chain reads the sectors linked from start in the allocation table
+// fat, truncated to size bytes unless size is negative.
+func (f *oleFile) chain(start uint32, fat []uint32, read func(uint32) ([]byte, error), size int64) ([]byte, error) {
+	var out []byte
+	for n, hops := start, 0; n != oleEndOfChain; hops++ {
+		// A chain longer than the table must loop.
+		if hops > len(fat) || int(n) >= len(fat) {
+			return nil, errOLECorrupt
+		}
+		sec, err := read(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sec...)
+		if size >= 0 && int64(len(out)) >= size {
+			break
+		}
+		if len(out) > maxDecompressed {
+			return nil, errors.New("compound file stream too large")
+		}
+		n = fat[n]
+	}
+	if size >= 0 {
+		if int64(len(out)) < size {
+			return nil, errOLECorrupt
+		}
+		out = out[:size]
+	}
+	return out, nil
+}
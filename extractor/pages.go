@@ -0,0 +1,225 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	readability "github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Limits on stitching a paginated article: how many pages are read in
+// all, and how long following them may take.
+const (
+	maxPages      = 10
+	maxStitchTime = 60 * time.Second
+)
+
+// pageParams are query parameters that number the pages of an article.
+// "p" is left out: WordPress uses ?p= for post IDs.
+var pageParams = []string{"page", "pg", "paged"}
+
+// pagePathPattern matches a page number at the end of a path, as in
+// /story/page/2. A bare trailing number (/story/2) is too often an
+// article ID to be trusted without a rel="next" link.
+var pagePathPattern = regexp.MustCompile(`^(.*?)/page/(\d{1,3})/?$`)
+
+// stitchPages follows the pagination links of an article's first page
+// and appends each further page to result. Only pages on the same host
+// are followed, each is checked with ValidateURL, and paragraphs and
+// headings already read on earlier pages (bylines, share prompts, the
+// repeated title) are dropped. A page that fails to load ends the
+// article where it is.
+func stitchPages(ctx context.Context, result *URLResult, first *downloaded) *URLResult {
+	ctx, cancel := context.WithTimeout(ctx, maxStitchTime)
+	defer cancel()
+
+	seen := make(map[string]bool)
+	var b docBuilder
+	for _, s := range result.Document.Sections {
+		seen[normalizeSpace(s.Heading)] = true
+		b.heading(s.Level, s.Heading)
+		for _, p := range s.Paragraphs {
+			seen[normalizeSpace(p)] = true
+			b.paragraph(p)
+		}
+	}
+
+	visited := map[string]bool{first.url.String(): true}
+	page := first
+	pages := 1
+	for pages < maxPages {
+		next := nextPageURL(page.data, page.url)
+		if next == nil || next.Hostname() != first.url.Hostname() || visited[next.String()] {
+			break
+		}
+		visited[next.String()] = true
+		if err := ValidateURL(ctx, next.String()); err != nil {
+			break
+		}
+
+		reportProgress(ctx, StageFetching, pages+1, 0)
+		dl, err := download(ctx, next.String())
+		if err != nil || sniffFormat(dl.contentType, dl.data, dl.url.Path) != ".html" {
+			break
+		}
+		article, err := readability.FromReader(bytes.NewReader(dl.data), dl.url)
+		if err != nil {
+			break
+		}
+		var pb docBuilder
+		if article.Node != nil {
+			pb.addHTML(article.Node)
+		}
+		for _, s := range pb.doc.Sections {
+			if key := normalizeSpace(s.Heading); key != "" && !seen[key] {
+				seen[key] = true
+				b.heading(s.Level, s.Heading)
+			}
+			for _, p := range s.Paragraphs {
+				if key := normalizeSpace(p); !seen[key] {
+					seen[key] = true
+					b.paragraph(p)
+				}
+			}
+		}
+		page = dl
+		pages++
+	}
+
+	if pages == 1 {
+		return result
+	}
+	result.Document = b.document(result.Document.Title)
+	result.Text = result.Document.Text()
+	return result
+}
+
+// nextPageURL finds the link to the page after cur: a rel="next" link
+// to the next page of the same article (or to cur's path plus the page
+// number, as in /story/2), or failing that an anchor to the same
+// article with the next page number.
+func nextPageURL(data []byte, cur *url.URL) *url.URL {
+	want := pageNumber(cur) + 1
+	curKey := pageKey(cur, 0)
+	var guess *url.URL
+
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return guess
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		name, hasAttr := z.TagName()
+		tag := atom.Lookup(name)
+		if tag != atom.Link && tag != atom.A {
+			continue
+		}
+		var rel, href string
+		for hasAttr {
+			var key, val []byte
+			key, val, hasAttr = z.TagAttr()
+			switch string(key) {
+			case "rel":
+				rel = strings.ToLower(string(val))
+			case "href":
+				href = string(val)
+			}
+		}
+		u, err := cur.Parse(href)
+		if href == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		u.Fragment = ""
+		samePage := pageKey(u, want) == curKey
+		if hasToken(rel, "next") && (samePage || isNumberedSubpage(u, cur, want)) {
+			return u
+		}
+		if samePage && guess == nil && tag == atom.A {
+			guess = u
+		}
+	}
+}
+
+// isNumberedSubpage reports whether u is cur's path with the page number
+// appended, as in /story → /story/2 or /story/2 → /story/3.
+func isNumberedSubpage(u, cur *url.URL, page int) bool {
+	base := strings.TrimSuffix(cur.Path, "/")
+	if page > 2 {
+		base = strings.TrimSuffix(base, "/"+strconv.Itoa(page-1))
+	}
+	return strings.EqualFold(u.Host, cur.Host) && u.RawQuery == cur.RawQuery &&
+		strings.TrimSuffix(u.Path, "/") == base+"/"+strconv.Itoa(page)
+}
+
+// pageNumber returns the page number in u, or 1 if it has none.
+func pageNumber(u *url.URL) int {
+	q := u.Query()
+	for _, p := range pageParams {
+		if n, err := strconv.Atoi(q.Get(p)); err == nil && n > 0 {
+			return n
+		}
+	}
+	if m := pagePathPattern.FindStringSubmatch(u.Path); m != nil {
+		if n, _ := strconv.Atoi(m[2]); n > 0 {
+			return n
+		}
+	}
+	return 1
+}
+
+// pageKey identifies the article u is a page of. If page is not zero,
+// the key is only returned when u is that page; otherwise it is "-".
+func pageKey(u *url.URL, page int) string {
+	if page != 0 && pageNumber(u) != page {
+		return "-"
+	}
+	q := u.Query()
+	numbered := false
+	for _, p := range pageParams {
+		if q.Has(p) {
+			q.Del(p)
+			numbered = true
+		}
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if m := pagePathPattern.FindStringSubmatch(u.Path); m != nil && !numbered {
+		path = m[1]
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(strings.ToLower(u.Host) + path + "?")
+	for _, k := range keys {
+		b.WriteString(url.QueryEscape(k) + "=" + url.QueryEscape(strings.Join(q[k], ",")) + "&")
+	}
+	return b.String()
+}
+
+// hasToken reports whether the space-separated list s contains tok.
+func hasToken(s, tok string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == tok {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSpace collapses runs of whitespace, for comparing paragraphs.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
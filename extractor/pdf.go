@@ -1,43 +1,194 @@
 package extractor
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 
 	"github.com/ledongthuc/pdf"
 )
 
-// ExtractPDF reads a PDF from an io.Reader and returns the plain text.
-// The ledongthuc/pdf library requires a file on disk, so we write to a temp file.
-func ExtractPDF(r io.Reader) (string, error) {
+// ExtractPDF reads a PDF from an io.Reader and returns its content as a
+// Document. The ledongthuc/pdf library requires a file on disk, so we
+// write to a temp file.
+//
+// Sections come from the PDF outline (bookmarks) when it has one; each
+// outline entry starts a section at the top of the page it points to.
+// Pages are reported to the context's Progress as they are read, and
+// canceling ctx stops at the next page.
+func ExtractPDF(ctx context.Context, r io.Reader) (*Document, error) {
 	tmp, err := os.CreateTemp("", "read-aloud-*.pdf")
 	if err != nil {
-		return "", fmt.Errorf("create temp file: %w", err)
+		return nil, fmt.Errorf("create temp file: %w", err)
 	}
 	defer os.Remove(tmp.Name())
 	defer tmp.Close()
 
-	if _, err := io.Copy(tmp, r); err != nil {
-		return "", fmt.Errorf("write temp file: %w", err)
+	if _, err := io.Copy(tmp, ctxReader{ctx, r}); err != nil {
+		return nil, fmt.Errorf("write temp file: %w", err)
 	}
 	tmp.Close()
 
 	f, reader, err := pdf.Open(tmp.Name())
 	if err != nil {
-		return "", fmt.Errorf("open PDF: %w", err)
+		return nil, fmt.Errorf("open PDF: %w", err)
 	}
 	defer f.Close()
 
-	plainText, err := reader.GetPlainText()
-	if err != nil {
-		return "", fmt.Errorf("extract text: %w", err)
+	numPages := reader.NumPage()
+	pages := make([]pdf.Value, numPages)
+	for i := range pages {
+		pages[i] = reader.Page(i + 1).V
+	}
+	title, headings := pdfTitleAndOutline(reader, pages)
+
+	var b docBuilder
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= numPages; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reportProgress(ctx, StagePage, i, numPages)
+		for _, h := range headings[i] {
+			b.heading(h.level, h.title)
+		}
+
+		p := reader.Page(i)
+		for _, name := range p.Fonts() { // cache fonts so we don't continually parse charmap
+			if _, ok := fonts[name]; !ok {
+				f := p.Font(name)
+				fonts[name] = &f
+			}
+		}
+		text, err := p.GetPlainText(fonts)
+		if err != nil {
+			return nil, fmt.Errorf("extract text: %w", err)
+		}
+		for _, para := range strings.Split(text, "\n\n") {
+			b.paragraph(para)
+		}
+	}
+
+	doc := b.document(title)
+	doc.Metadata = pdfMetadata(reader)
+	return doc, nil
+}
+
+// pdfTitleAndOutline reads the document title and outline headings.
+// ledongthuc/pdf panics on malformed object references and only its
+// text extraction recovers, so each lookup is guarded on its own: a
+// broken Info entry leaves the title empty (callers fall back to the
+// filename) and a broken outline leaves the document without headings.
+func pdfTitleAndOutline(reader *pdf.Reader, pages []pdf.Value) (title string, headings map[int][]pdfHeading) {
+	pdfGuard(func() { title = reader.Trailer().Key("Info").Key("Title").Text() })
+	pdfGuard(func() { headings = pdfOutlineHeadings(reader, pages) })
+	return title, headings
+}
+
+// pdfGuard runs fn and swallows any panic from the PDF library.
+func pdfGuard(fn func()) {
+	defer func() { recover() }()
+	fn()
+}
+
+// pdfHeading is an outline entry resolved to a heading level.
+type pdfHeading struct {
+	title string
+	level int
+}
+
+// pdfOutlineHeadings walks the document outline and returns its entries
+// keyed by 1-based page number. Entries whose destination cannot be
+// resolved to a page, or that are nested deeper than maxSectionLevel,
+// are dropped.
+func pdfOutlineHeadings(reader *pdf.Reader, pages []pdf.Value) map[int][]pdfHeading {
+	headings := make(map[int][]pdfHeading)
+	root := reader.Trailer().Key("Root")
+
+	// Outlines can be cyclic in malformed files; cap the walk.
+	visited := 0
+	var walk func(entry pdf.Value, level int)
+	walk = func(entry pdf.Value, level int) {
+		for item := entry.Key("First"); item.Kind() == pdf.Dict; item = item.Key("Next") {
+			if visited++; visited > 10000 {
+				return
+			}
+			if level > maxSectionLevel {
+				return
+			}
+			if page := pdfDestPage(root, pdfOutlineDest(item), pages); page > 0 {
+				title := strings.TrimSpace(item.Key("Title").Text())
+				headings[page] = append(headings[page], pdfHeading{title, level})
+			}
+			walk(item, level+1)
+		}
 	}
+	walk(root.Key("Outlines"), 1)
 
-	var buf bytes.Buffer
-	buf.ReadFrom(plainText)
+	return headings
+}
+
+// pdfOutlineDest returns an outline item's destination, either given
+// directly or through a GoTo action.
+func pdfOutlineDest(item pdf.Value) pdf.Value {
+	if dest := item.Key("Dest"); !dest.IsNull() {
+		return dest
+	}
+	if action := item.Key("A"); action.Key("S").Name() == "GoTo" {
+		return action.Key("D")
+	}
+	return pdf.Value{}
+}
 
-	return strings.TrimSpace(buf.String()), nil
+// pdfDestPage resolves a destination to a 1-based page number, or 0.
+// Explicit destinations are arrays whose first element is the page
+// object; named destinations are looked up in the catalog's Dests
+// dictionary or Names tree first.
+func pdfDestPage(root, dest pdf.Value, pages []pdf.Value) int {
+	switch dest.Kind() {
+	case pdf.Name:
+		dest = root.Key("Dests").Key(dest.Name())
+	case pdf.String:
+		dest = pdfLookupName(root.Key("Names").Key("Dests"), dest.RawString(), 0)
+	}
+	// A named destination may be wrapped in a dictionary with a D key.
+	if dest.Kind() == pdf.Dict {
+		dest = dest.Key("D")
+	}
+	if dest.Kind() != pdf.Array || dest.Len() == 0 {
+		return 0
+	}
+
+	target := dest.Index(0)
+	for i, page := range pages {
+		// pdf.Value has no exported identity; resolved values of the
+		// same object compare equal.
+		if reflect.DeepEqual(page, target) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// pdfLookupName finds key in a PDF name tree.
+func pdfLookupName(node pdf.Value, key string, depth int) pdf.Value {
+	if node.Kind() != pdf.Dict || depth > 32 {
+		return pdf.Value{}
+	}
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		if names.Index(i).RawString() == key {
+			return names.Index(i + 1)
+		}
+	}
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		if v := pdfLookupName(kids.Index(i), key, depth+1); !v.IsNull() {
+			return v
+		}
+	}
+	return pdf.Value{}
 }
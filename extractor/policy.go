@@ -0,0 +1,268 @@
+package extractor
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Environment variables holding extra egress rules: comma- or
+// space-separated IPs, CIDR ranges and hostnames. A hostname starting
+// with "*." matches every subdomain.
+const (
+	EnvEgressAllow = "READ_ALOUD_EGRESS_ALLOW"
+	EnvEgressDeny  = "READ_ALOUD_EGRESS_DENY"
+)
+
+// Rule is one entry of an egress policy: an IP range or a hostname
+// pattern, with the name shown when it blocks a URL.
+type Rule struct {
+	Name   string
+	Prefix netip.Prefix // zero for hostname rules
+	Host   string       // lower case; "*.example.com" matches subdomains
+
+	// Strict, on a default rule, means allowing a hostname does not
+	// let it resolve into the range; only an IP allow rule does.
+	Strict bool
+}
+
+// matchIP reports whether ip is in the rule's range.
+func (r Rule) matchIP(ip netip.Addr) bool {
+	return r.Prefix.IsValid() && r.Prefix.Contains(ip)
+}
+
+// matchHost reports whether host matches the rule's hostname pattern.
+func (r Rule) matchHost(host string) bool {
+	if r.Host == "" {
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(r.Host, "*"); ok {
+		return strings.HasSuffix(host, suffix)
+	}
+	return host == r.Host
+}
+
+// Policy decides which hosts and IPs URL fetching may reach. Deny rules
+// win over allow rules, and allow rules win over the defaults, so a
+// team can open up an intranet wiki without opening up the rest of its
+// network. An allowed hostname still may not resolve to a strict
+// default, such as loopback or cloud metadata: whoever controls its DNS
+// could otherwise point it there.
+type Policy struct {
+	Allow    []Rule
+	Deny     []Rule
+	Defaults []Rule // denied unless allowed; see DefaultRules
+}
+
+// BlockedError reports which rule blocked a URL. It matches
+// ErrBlockedHost with errors.Is.
+type BlockedError struct {
+	Target string // the hostname or IP that was checked
+	Host   string // the URL's hostname, if Target is one of its IPs
+	Rule   Rule
+	Denied bool // blocked by a deny rule rather than a default
+}
+
+func (e *BlockedError) Error() string {
+	target := e.Target
+	if e.Host != "" && e.Host != e.Target {
+		target = fmt.Sprintf("%s (%s)", e.Host, e.Target)
+	}
+	if e.Denied {
+		return fmt.Sprintf("blocked: %s matches deny rule %s", target, e.Rule.Name)
+	}
+	if e.Rule.Host != "" {
+		return fmt.Sprintf("blocked: %s is a reserved hostname; add it to %s to allow it",
+			target, EnvEgressAllow)
+	}
+	if e.Rule.Strict {
+		return fmt.Sprintf("blocked: %s is in %s; add the address to %s to allow it",
+			target, e.Rule.Name, EnvEgressAllow)
+	}
+	return fmt.Sprintf("blocked: %s is in %s; add it to %s to allow it",
+		target, e.Rule.Name, EnvEgressAllow)
+}
+
+func (e *BlockedError) Is(target error) bool { return target == ErrBlockedHost }
+
+// CheckHost checks a URL's hostname before it is resolved.
+func (p *Policy) CheckHost(host string) error {
+	host = normalizeHost(host)
+	if r, ok := findRule(p.Deny, func(r Rule) bool { return r.matchHost(host) }); ok {
+		return &BlockedError{Target: host, Rule: r, Denied: true}
+	}
+	if _, ok := findRule(p.Allow, func(r Rule) bool { return r.matchHost(host) }); ok {
+		return nil
+	}
+	if r, ok := findRule(p.Defaults, func(r Rule) bool { return r.matchHost(host) }); ok {
+		return &BlockedError{Target: host, Rule: r}
+	}
+	return nil
+}
+
+// CheckIP checks an address that host resolved to. host may be empty
+// for IP-only checks; when it matches an allow rule the address is
+// allowed unless a deny rule or a strict default says otherwise.
+func (p *Policy) CheckIP(host string, ip netip.Addr) error {
+	host = normalizeHost(host)
+	ip = ip.Unmap()
+	// A NAT64 address reaches the IPv4 address embedded in it.
+	if nat64.Contains(ip) {
+		b := ip.As16()
+		ip = netip.AddrFrom4([4]byte(b[12:]))
+	}
+
+	if r, ok := findRule(p.Deny, func(r Rule) bool { return r.matchIP(ip) }); ok {
+		return &BlockedError{Target: ip.String(), Host: host, Rule: r, Denied: true}
+	}
+	if _, ok := findRule(p.Allow, func(r Rule) bool { return r.matchIP(ip) }); ok {
+		return nil
+	}
+	r, ok := findRule(p.Defaults, func(r Rule) bool { return r.matchIP(ip) })
+	if !ok {
+		return nil
+	}
+	if !r.Strict {
+		if _, ok := findRule(p.Allow, func(r Rule) bool { return r.matchHost(host) }); ok {
+			return nil
+		}
+	}
+	return &BlockedError{Target: ip.String(), Host: host, Rule: r}
+}
+
+// nat64 is the well-known NAT64 prefix (RFC 6052).
+var nat64 = netip.MustParsePrefix("64:ff9b::/96")
+
+func findRule(rules []Rule, match func(Rule) bool) (Rule, bool) {
+	for _, r := range rules {
+		if match(r) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// DefaultRules returns the ranges and hostnames blocked unless allowed:
+// the IANA special-purpose registries' ranges that are not globally
+// reachable, multicast, and cloud metadata endpoints. Unspecified,
+// loopback, link-local and metadata addresses are strict: only an IP
+// or CIDR allow rule opens them.
+func DefaultRules() []Rule {
+	ranges := []struct {
+		cidr, name string
+		strict     bool
+	}{
+		{"0.0.0.0/8", `"this network"`, true},
+		{"10.0.0.0/8", "private-use network", false},
+		{"100.64.0.0/10", "shared address space, CGNAT", false},
+		{"127.0.0.0/8", "loopback", true},
+		{"169.254.0.0/16", "link-local, incl. cloud metadata", true},
+		{"172.16.0.0/12", "private-use network", false},
+		{"192.0.0.0/24", "IETF protocol assignments", false},
+		{"192.0.2.0/24", "documentation, TEST-NET-1", false},
+		{"192.88.99.0/24", "6to4 relay anycast", false},
+		{"192.168.0.0/16", "private-use network", false},
+		{"198.18.0.0/15", "benchmarking", false},
+		{"198.51.100.0/24", "documentation, TEST-NET-2", false},
+		{"203.0.113.0/24", "documentation, TEST-NET-3", false},
+		{"224.0.0.0/4", "multicast", false},
+		{"240.0.0.0/4", "reserved and broadcast", false},
+		{"::/128", "unspecified address", true},
+		{"::1/128", "loopback", true},
+		{"64:ff9b:1::/48", "local-use NAT64", false},
+		{"100::/64", "discard-only", false},
+		{"2001::/23", "IETF protocol assignments", false},
+		{"2001:db8::/32", "documentation", false},
+		{"2002::/16", "6to4", false},
+		{"3fff::/20", "documentation", false},
+		{"5f00::/16", "segment routing, SRv6", false},
+		{"fd00:ec2::254/128", "cloud metadata", true},
+		{"fc00::/7", "unique-local", false},
+		{"fe80::/10", "link-local", true},
+		{"fec0::/10", "site-local", false},
+		{"ff00::/8", "multicast", false},
+	}
+	rules := make([]Rule, 0, len(ranges)+3)
+	for _, r := range ranges {
+		p := netip.MustParsePrefix(r.cidr)
+		rules = append(rules, Rule{Name: fmt.Sprintf("%s (%s)", p, r.name), Prefix: p, Strict: r.strict})
+	}
+	for _, h := range []string{"localhost", "*.localhost", "metadata.google.internal"} {
+		rules = append(rules, Rule{Name: h, Host: h})
+	}
+	return rules
+}
+
+// DefaultPolicy returns a policy with only the default rules.
+func DefaultPolicy() *Policy {
+	return &Policy{Defaults: DefaultRules()}
+}
+
+// PolicyFromEnv returns the default policy plus the rules in
+// READ_ALOUD_EGRESS_ALLOW and READ_ALOUD_EGRESS_DENY.
+func PolicyFromEnv() (*Policy, error) {
+	p := DefaultPolicy()
+	var err error
+	if p.Allow, err = ParseRules(os.Getenv(EnvEgressAllow)); err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvEgressAllow, err)
+	}
+	if p.Deny, err = ParseRules(os.Getenv(EnvEgressDeny)); err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvEgressDeny, err)
+	}
+	return p, nil
+}
+
+// ParseRules parses a comma- or space-separated list of IPs, CIDR
+// ranges and hostnames.
+func ParseRules(list string) ([]Rule, error) {
+	var rules []Rule
+	fields := strings.FieldsFunc(list, func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t' || c == '\n'
+	})
+	for _, f := range fields {
+		r, err := parseRule(f)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func parseRule(s string) (Rule, error) {
+	if p, err := netip.ParsePrefix(s); err == nil {
+		return Rule{Name: s, Prefix: p.Masked()}, nil
+	}
+	if ip, err := netip.ParseAddr(s); err == nil {
+		ip = ip.Unmap()
+		return Rule{Name: s, Prefix: netip.PrefixFrom(ip, ip.BitLen())}, nil
+	}
+	host := normalizeHost(s)
+	name := strings.TrimPrefix(host, "*.")
+	if name == "" || strings.ContainsAny(name, "*/:@ ") {
+		return Rule{}, fmt.Errorf("invalid rule %q: want an IP, CIDR range or hostname", s)
+	}
+	if host != name {
+		host = "*." + name
+	}
+	return Rule{Name: s, Host: host}, nil
+}
+
+// currentPolicy is the policy URL fetching uses.
+var currentPolicy atomic.Pointer[Policy]
+
+func init() { currentPolicy.Store(DefaultPolicy()) }
+
+// SetPolicy replaces the egress policy for all later fetches.
+func SetPolicy(p *Policy) {
+	if p == nil {
+		p = DefaultPolicy()
+	}
+	currentPolicy.Store(p)
+}
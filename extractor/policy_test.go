@@ -0,0 +1,71 @@
+package extractor
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPolicyCheckIP(t *testing.T) {
+	allow, err := ParseRules("wiki.corp, 10.20.0.0/16, 127.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deny, err := ParseRules("10.20.30.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Policy{Allow: allow, Deny: deny, Defaults: DefaultRules()}
+
+	tests := []struct {
+		host, ip string
+		allowed  bool
+	}{
+		{"example.com", "93.184.215.14", true},
+		{"example.com", "10.1.2.3", false},
+		{"example.com", "10.20.1.1", true},   // CIDR allow
+		{"example.com", "10.20.30.1", false}, // deny wins over allow
+		{"wiki.corp", "192.168.1.10", true},  // hostname allow opens private ranges
+		{"wiki.corp", "fd12:3456::1", true},  // ... and unique-local
+		{"wiki.corp", "127.0.0.1", false},    // but not loopback
+		{"wiki.corp", "::1", false},
+		{"wiki.corp", "169.254.169.254", false}, // or cloud metadata
+		{"wiki.corp", "fd00:ec2::254", false},
+		{"wiki.corp", "0.0.0.0", false}, // or unspecified
+		{"wiki.corp", "::ffff:127.0.0.1", false},
+		{"wiki.corp", "64:ff9b::a9fe:a9fe", false}, // NAT64 of 169.254.169.254
+		{"", "127.0.0.2", true},                    // an explicit IP allow opens it
+	}
+	for _, tt := range tests {
+		err := p.CheckIP(tt.host, netip.MustParseAddr(tt.ip))
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("CheckIP(%q, %s) = %v, want allowed %v", tt.host, tt.ip, err, tt.allowed)
+		}
+		if err != nil && !errors.Is(err, ErrBlockedHost) {
+			t.Errorf("CheckIP(%q, %s) error %v is not ErrBlockedHost", tt.host, tt.ip, err)
+		}
+	}
+}
+
+func TestPolicyCheckHost(t *testing.T) {
+	allow, _ := ParseRules("*.corp")
+	deny, _ := ParseRules("ads.example")
+	p := &Policy{Allow: allow, Deny: deny, Defaults: DefaultRules()}
+
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"example.com", true},
+		{"ads.example", false},
+		{"localhost", false},
+		{"api.localhost.", false},
+		{"metadata.google.internal", false},
+		{"wiki.corp", true},
+	}
+	for _, tt := range tests {
+		if allowed := p.CheckHost(tt.host) == nil; allowed != tt.allowed {
+			t.Errorf("CheckHost(%q) allowed = %v, want %v", tt.host, allowed, tt.allowed)
+		}
+	}
+}
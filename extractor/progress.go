@@ -0,0 +1,28 @@
+package extractor
+
+import "context"
+
+// Extraction stages reported to a Progress callback.
+const (
+	StageFetching = "fetching" // downloading the page or file
+	StageParsing  = "parsing"  // turning the download into text
+	StagePage     = "page"     // PDF page done of total
+)
+
+// Progress receives extraction progress. done and total are only set
+// for stages that count something, such as StagePage.
+type Progress func(stage string, done, total int)
+
+type progressKey struct{}
+
+// WithProgress returns a context that reports extraction progress to fn.
+func WithProgress(ctx context.Context, fn Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress calls the Progress callback in ctx, if any.
+func reportProgress(ctx context.Context, stage string, done, total int) {
+	if fn, ok := ctx.Value(progressKey{}).(Progress); ok {
+		fn(stage, done, total)
+	}
+}
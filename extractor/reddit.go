@@ -0,0 +1,128 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// redditBase is where Reddit's JSON view of a thread is read from.
+var redditBase = "https://www.reddit.com"
+
+// redditPathPattern matches a thread path and captures it without any
+// comment slug or trailing slash, e.g. /r/golang/comments/abc123.
+var redditPathPattern = regexp.MustCompile(`^(/r/\w+/comments/\w+)(/|$)`)
+
+// redditSite reads a Reddit post and its comments through the JSON view
+// Reddit serves for every thread.
+var redditSite = SiteExtractor{
+	Name: "Reddit",
+	Match: func(u *url.URL) bool {
+		return hostIs(u, "reddit.com", true) && redditPathPattern.MatchString(u.Path)
+	},
+	Extract: extractReddit,
+}
+
+// redditListing is one element of a thread's JSON view.
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Kind string     `json:"kind"`
+			Data redditPost `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// redditPost is a post (t3) or comment (t1). Replies is a listing, or ""
+// for a comment without replies.
+type redditPost struct {
+	Title      string          `json:"title"`
+	Author     string          `json:"author"`
+	Selftext   string          `json:"selftext"`
+	Body       string          `json:"body"`
+	URL        string          `json:"url"`
+	IsSelf     bool            `json:"is_self"`
+	Subreddit  string          `json:"subreddit_name_prefixed"`
+	CreatedUTC float64         `json:"created_utc"`
+	Permalink  string          `json:"permalink"`
+	Replies    json.RawMessage `json:"replies"`
+}
+
+// redditComments converts the t1 entries of a listing, skipping the
+// "load more" stubs.
+func redditComments(l *redditListing) []threadComment {
+	var comments []threadComment
+	for _, c := range l.Data.Children {
+		if c.Kind != "t1" {
+			continue
+		}
+		tc := threadComment{author: c.Data.Author}
+		if c.Data.Author == "[deleted]" {
+			tc.author = ""
+		}
+		if c.Data.Body != "[deleted]" && c.Data.Body != "[removed]" {
+			tc.paragraphs = markdownParagraphs(c.Data.Body)
+		}
+		var replies redditListing
+		if len(c.Data.Replies) > 0 && c.Data.Replies[0] == '{' &&
+			json.Unmarshal(c.Data.Replies, &replies) == nil {
+			tc.replies = redditComments(&replies)
+		}
+		comments = append(comments, tc)
+	}
+	return comments
+}
+
+func extractReddit(ctx context.Context, u *url.URL) (*URLResult, error) {
+	path := redditPathPattern.FindStringSubmatch(u.Path)[1]
+	endpoint := redditBase + path + ".json?raw_json=1"
+	// Reddit turns away requests with a generic client User-Agent.
+	header := http.Header{"User-Agent": {"read-aloud/1.0 (article reader)"}}
+
+	var listings []redditListing
+	if err := fetchJSON(ctx, endpoint, header, &listings); err != nil {
+		return nil, err
+	}
+	if len(listings) == 0 || len(listings[0].Data.Children) == 0 {
+		return nil, fmt.Errorf("no post found at %s", path)
+	}
+	post := listings[0].Data.Children[0].Data
+
+	var b docBuilder
+	if !post.IsSelf && post.URL != "" {
+		if link, err := url.Parse(post.URL); err == nil && link.Host != "" {
+			b.paragraph("Link to " + link.Hostname() + ".")
+		}
+	}
+	for _, p := range markdownParagraphs(post.Selftext) {
+		b.paragraph(p)
+	}
+	if len(listings) > 1 {
+		if comments := redditComments(&listings[1]); len(comments) > 0 {
+			b.heading(2, "Comments")
+			b.addThread(comments)
+		}
+	}
+
+	doc := b.document(post.Title)
+	m := Metadata{
+		Byline:   "u/" + post.Author,
+		SiteName: "Reddit",
+		FinalURL: "https://www.reddit.com" + path,
+	}
+	if post.Subreddit != "" {
+		m.SiteName = post.Subreddit
+	}
+	if post.Permalink != "" {
+		m.FinalURL = "https://www.reddit.com" + post.Permalink
+	}
+	if post.CreatedUTC > 0 {
+		t := time.Unix(int64(post.CreatedUTC), 0).UTC()
+		m.Published = &t
+	}
+	return &URLResult{Title: doc.Title, Text: doc.Text(), Document: doc, Metadata: m}, nil
+}
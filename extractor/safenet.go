@@ -2,95 +2,64 @@ package extractor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/netip"
 	"net/url"
-	"strings"
 	"time"
 )
 
-// ErrBlockedHost is returned when a URL resolves to a blocked IP.
-var ErrBlockedHost = fmt.Errorf("blocked: host resolves to a private or reserved IP")
+// ErrBlockedHost is returned when a URL is blocked by the egress policy.
+// The error returned is a *BlockedError naming the rule.
+var ErrBlockedHost = errors.New("blocked: host resolves to a private or reserved IP")
 
 // ValidateURL checks that a URL is safe to fetch. It rejects non-HTTP(S)
-// schemes, hostnames that resolve to private/reserved IPs, and known
-// cloud metadata endpoints.
-func ValidateURL(rawURL string) error {
+// schemes, and hostnames or resolved IPs blocked by the egress policy
+// (by default: private, reserved and special-purpose ranges and known
+// cloud metadata endpoints). ctx bounds the DNS lookup.
+//
+// It gives an early, clear error; fetchClient repeats the IP check on
+// every connection it makes.
+func ValidateURL(ctx context.Context, rawURL string) error {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return fmt.Errorf("invalid URL: %w", err)
 	}
-
-	scheme := strings.ToLower(u.Scheme)
-	if scheme != "http" && scheme != "https" {
-		return fmt.Errorf("unsupported scheme: %s", scheme)
+	if err := checkURL(u); err != nil {
+		return err
 	}
 
 	host := u.Hostname()
-	if host == "" {
-		return fmt.Errorf("empty hostname")
-	}
-
-	if isBlockedHostname(host) {
-		return ErrBlockedHost
-	}
-
-	ips, err := resolveHost(host)
+	ips, err := resolveHost(ctx, host)
 	if err != nil {
 		return fmt.Errorf("DNS resolution failed: %w", err)
 	}
 
+	policy := currentPolicy.Load()
 	for _, ip := range ips {
-		if isPrivateIP(ip) {
-			return ErrBlockedHost
+		if err := policy.CheckIP(host, ip); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// blockedHostnames contains hostnames that should never be fetched.
-var blockedHostnames = map[string]bool{
-	"localhost":                true,
-	"metadata.google.internal": true,
-}
-
-func isBlockedHostname(host string) bool {
-	h := strings.ToLower(host)
-	return blockedHostnames[h]
-}
-
-func resolveHost(host string) ([]net.IP, error) {
-	if ip := net.ParseIP(host); ip != nil {
-		return []net.IP{ip}, nil
+func resolveHost(ctx context.Context, host string) ([]netip.Addr, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{ip}, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
 	if err != nil {
 		return nil, err
 	}
-
-	ips := make([]net.IP, 0, len(addrs))
-	for _, addr := range addrs {
-		ips = append(ips, addr.IP)
-	}
-	return ips, nil
-}
-
-func isPrivateIP(ip net.IP) bool {
-	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
-		ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
-		return true
-	}
-
-	// Block cloud metadata ranges: 169.254.169.254 and fd00:ec2::254.
-	metadata4 := net.ParseIP("169.254.169.254")
-	if ip.Equal(metadata4) {
-		return true
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
 	}
-
-	return false
+	return addrs, nil
 }
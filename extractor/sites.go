@@ -0,0 +1,186 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// SiteExtractor reads the pages of one site through the site's own API,
+// where generic extraction would get UI chrome or nothing at all. Match
+// reports whether the extractor handles a URL; Extract reads it.
+type SiteExtractor struct {
+	Name    string
+	Match   func(u *url.URL) bool
+	Extract func(ctx context.Context, u *url.URL) (*URLResult, error)
+}
+
+// errNotThisSite is returned by an Extract function whose Match was too
+// broad to be sure, such as Mastodon's, when the URL turns out not to
+// belong to its site. ExtractURL then reads the URL as a web page.
+var errNotThisSite = errors.New("not a page of this site")
+
+// siteExtractors are tried in order by ExtractURL. Their API endpoints
+// are package variables so they can be pointed at test servers.
+var siteExtractors []SiteExtractor
+
+func init() {
+	// Set in init: extractors such as tweetSite lead back to ExtractURL
+	// through linked articles, which a variable initializer can't do.
+	siteExtractors = []SiteExtractor{
+		tweetSite,
+		wikipediaSite,
+		githubSite,
+		hackerNewsSite,
+		redditSite,
+		substackSite,
+		mediumSite,
+		blueskySite,
+		mastodonSite, // last: it matches paths on any host
+	}
+}
+
+// matchSite returns the site extractor for u, or nil.
+func matchSite(u *url.URL) *SiteExtractor {
+	for i := range siteExtractors {
+		if siteExtractors[i].Match(u) {
+			return &siteExtractors[i]
+		}
+	}
+	return nil
+}
+
+// maxAPIResponse caps the size of a site API response.
+const maxAPIResponse = 10 << 20
+
+// fetchJSON GETs rawURL and decodes its JSON body into v.
+func fetchJSON(ctx context.Context, rawURL string, header http.Header, v any) error {
+	reportProgress(ctx, StageFetching, 0, 0)
+	resp, err := fetchWithHeader(ctx, rawURL, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	reportProgress(ctx, StageParsing, 0, 0)
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAPIResponse)).Decode(v); err != nil {
+		return fmt.Errorf("parse %s response: %w", resp.Request.URL.Host, err)
+	}
+	return nil
+}
+
+// hostIs reports whether u's host is domain or, with subdomains set,
+// one of its subdomains.
+func hostIs(u *url.URL, domain string, subdomains bool) bool {
+	host := normalizeHost(u.Hostname())
+	return host == domain || subdomains && strings.HasSuffix(host, "."+domain)
+}
+
+// htmlParagraphs returns the paragraphs of an HTML fragment, as used by
+// APIs that return comment or post bodies as HTML.
+func htmlParagraphs(fragment string) []string {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type: html.ElementNode, Data: "body", DataAtom: atom.Body,
+	})
+	if err != nil {
+		return nil
+	}
+	var b docBuilder
+	for _, n := range nodes {
+		b.addHTML(n)
+	}
+	var paragraphs []string
+	for _, s := range b.doc.Sections {
+		if s.Heading != "" {
+			paragraphs = append(paragraphs, s.Heading)
+		}
+		paragraphs = append(paragraphs, s.Paragraphs...)
+	}
+	return paragraphs
+}
+
+// threadComment is one comment of a discussion thread, in a form shared
+// by the Hacker News and Reddit extractors.
+type threadComment struct {
+	author     string
+	paragraphs []string
+	replies    []threadComment
+}
+
+// maxThreadComments bounds how many comments of a thread are read.
+const maxThreadComments = 200
+
+// addThread adds comments depth-first, announcing each one's author so
+// the listener can follow who is replying to whom.
+func (b *docBuilder) addThread(comments []threadComment) {
+	count := 0
+	var walk func(cs []threadComment, depth int)
+	walk = func(cs []threadComment, depth int) {
+		for _, c := range cs {
+			if count >= maxThreadComments {
+				return
+			}
+			if len(c.paragraphs) == 0 {
+				walk(c.replies, depth+1)
+				continue
+			}
+			count++
+			verb := "wrote"
+			if depth > 0 {
+				verb = "replied"
+			}
+			author := c.author
+			if author == "" {
+				author = "Someone"
+			}
+			b.paragraph(fmt.Sprintf("%s %s: %s", author, verb, c.paragraphs[0]))
+			for _, p := range c.paragraphs[1:] {
+				b.paragraph(p)
+			}
+			walk(c.replies, depth+1)
+		}
+	}
+	walk(comments, 0)
+}
+
+// maxThreadPosts bounds how many posts of a social media thread are read.
+const maxThreadPosts = 50
+
+// quotedPost labels the paragraphs of a quoted post so the listener can
+// tell where the quote starts and ends.
+func quotedPost(name, handle string, paragraphs []string) []string {
+	if len(paragraphs) == 0 {
+		return nil
+	}
+	by := strings.TrimSpace(fmt.Sprintf("%s (@%s)", name, handle))
+	if name == "" {
+		by = "@" + handle
+	}
+	quoted := append([]string{fmt.Sprintf("Quoting %s: %s", by, paragraphs[0])}, paragraphs[1:]...)
+	return append(quoted, "End of quote.")
+}
+
+// postsResult reads one author's posts as a document, titled
+// "Post by @handle" or, for several posts, "Thread by @handle".
+func postsResult(handle string, posts [][]string, m Metadata) *URLResult {
+	title := fmt.Sprintf("Post by @%s", handle)
+	if len(posts) > 1 {
+		title = fmt.Sprintf("Thread by @%s", handle)
+	}
+	var b docBuilder
+	for _, post := range posts {
+		for _, p := range post {
+			b.paragraph(p)
+		}
+	}
+	doc := b.document(title)
+	return &URLResult{Title: title, Text: doc.Text(), Document: doc, Metadata: m}
+}
@@ -0,0 +1,381 @@
+package extractor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureServer serves canned responses for the site API tests. Fetches
+// go through the egress policy, so loopback is allowed while it runs.
+func fixtureServer(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	prev := currentPolicy.Load()
+	SetPolicy(&Policy{
+		Allow: []Rule{
+			{Name: "test loopback", Prefix: netip.MustParsePrefix("127.0.0.0/8")},
+			{Name: "test loopback", Prefix: netip.MustParsePrefix("::1/128")},
+		},
+		Defaults: DefaultRules(),
+	})
+	t.Cleanup(func() { SetPolicy(prev) })
+	return srv
+}
+
+// setEndpoint points a site's endpoint variable at a test server for
+// the rest of the test.
+func setEndpoint(t *testing.T, v *string, value string) {
+	t.Helper()
+	prev := *v
+	*v = value
+	t.Cleanup(func() { *v = prev })
+}
+
+// serveJSON responds with body as JSON.
+func serveJSON(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// checkSections compares a document's headings and paragraphs, written
+// one section per string as "heading|paragraph|paragraph".
+func checkSections(t *testing.T, doc *Document, want []string) {
+	t.Helper()
+	var got []string
+	for _, s := range doc.Sections {
+		got = append(got, strings.Join(append([]string{s.Heading}, s.Paragraphs...), "|"))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("sections:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMatchSite(t *testing.T) {
+	tests := []struct {
+		url  string
+		site string // "" for none
+	}{
+		{"https://en.wikipedia.org/wiki/Go_(programming_language)", "Wikipedia"},
+		{"https://www.wikipedia.org/wiki/Go", ""},
+		{"https://github.com/golang/go", "GitHub"},
+		{"https://github.com/golang/go/blob/master/README.md", "GitHub"},
+		{"https://github.com/golang/go/blob/master/main.go", ""},
+		{"https://github.com/topics/go", ""},
+		{"https://news.ycombinator.com/item?id=123", "Hacker News"},
+		{"https://news.ycombinator.com/news", ""},
+		{"https://old.reddit.com/r/golang/comments/abc123/some_title/", "Reddit"},
+		{"https://example.substack.com/p/a-post", "Substack"},
+		{"https://example.substack.com/archive", ""},
+		{"https://medium.com/@someone/a-story-123", "Medium"},
+		{"https://x.com/someone/status/123", "X"},
+		{"https://bsky.app/profile/someone.bsky.social/post/3kabc", "Bluesky"},
+		{"https://mastodon.social/@someone/123", "Mastodon"},
+		{"https://example.com/article", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if site := matchSite(mustParse(t, tt.url)); site != nil {
+			got = site.Name
+		}
+		if got != tt.site {
+			t.Errorf("matchSite(%s) = %q, want %q", tt.url, got, tt.site)
+		}
+	}
+}
+
+func TestWikipedia(t *testing.T) {
+	var query url.Values
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		serveJSON(`{"query": {"pages": [{
+			"title": "Go (programming language)",
+			"fullurl": "https://en.wikipedia.org/wiki/Go_(programming_language)",
+			"extract": "Go is a language.\n\n== History ==\nIt began in 2007.\n\n=== Naming ===\nGolang.\n\n== See also ==\nOther languages.\n\n=== Lists ===\nA list.\n\n== Design ==\nSimple."
+		}]}}`)(w, r)
+	}))
+	setEndpoint(t, &wikipediaAPI, srv.URL+"/{lang}/w/api.php")
+
+	result, err := extractWikipedia(context.Background(), mustParse(t, "https://en.wikipedia.org/wiki/Go_(programming_language)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := query.Get("titles"); got != "Go_(programming_language)" {
+		t.Errorf("titles = %q", got)
+	}
+	if result.Title != "Go (programming language)" || result.Language != "en" || result.SiteName != "Wikipedia" {
+		t.Errorf("result = %q, %q, %q", result.Title, result.Language, result.SiteName)
+	}
+	checkSections(t, result.Document, []string{
+		"|Go is a language.",
+		"History|It began in 2007.",
+		"Naming|Golang.",
+		"Design|Simple.",
+	})
+}
+
+func TestWikipediaMissing(t *testing.T) {
+	srv := fixtureServer(t, serveJSON(`{"query": {"pages": [{"title": "Nope", "missing": true}]}}`))
+	setEndpoint(t, &wikipediaAPI, srv.URL+"/{lang}/w/api.php")
+
+	if _, err := extractWikipedia(context.Background(), mustParse(t, "https://en.wikipedia.org/wiki/Nope")); err == nil {
+		t.Error("missing article extracted")
+	}
+}
+
+func TestGitHubReadme(t *testing.T) {
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/readme" || r.Header.Get("Accept") != "application/vnd.github.raw" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("# Project\n\nIt does **things**.\n\n## Install\n\nRun it."))
+	}))
+	setEndpoint(t, &githubAPI, srv.URL)
+
+	result, err := extractGitHub(context.Background(), mustParse(t, "https://github.com/o/r"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Title != "o/r" || result.Byline != "o" {
+		t.Errorf("title %q, byline %q", result.Title, result.Byline)
+	}
+	checkSections(t, result.Document, []string{
+		"Project|It does things.",
+		"Install|Run it.",
+	})
+}
+
+func TestGitHubBlobSlashRef(t *testing.T) {
+	var tried []string
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tried = append(tried, r.URL.Path+"@"+r.URL.Query().Get("ref"))
+		if r.URL.Path == "/repos/o/r/contents/docs/GUIDE.md" && r.URL.Query().Get("ref") == "feature/x" {
+			w.Write([]byte("Guide text."))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	setEndpoint(t, &githubAPI, srv.URL)
+
+	result, err := extractGitHub(context.Background(), mustParse(t, "https://github.com/o/r/blob/feature/x/docs/GUIDE.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/repos/o/r/contents/x/docs/GUIDE.md@feature", "/repos/o/r/contents/docs/GUIDE.md@feature/x"}
+	if strings.Join(tried, " ") != strings.Join(want, " ") {
+		t.Errorf("requests = %q, want %q", tried, want)
+	}
+	if result.Title != "o/r: GUIDE.md" || result.Text != "Guide text." {
+		t.Errorf("title %q, text %q", result.Title, result.Text)
+	}
+}
+
+func TestGitHubBlobNotFound(t *testing.T) {
+	requests := 0
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	setEndpoint(t, &githubAPI, srv.URL)
+
+	_, err := extractGitHub(context.Background(), mustParse(t, "https://github.com/o/r/blob/a/b/c/d/e/f/README.md"))
+	if !isStatus(err, http.StatusNotFound) {
+		t.Errorf("err = %v, want a 404", err)
+	}
+	if requests != maxGitHubRefTries {
+		t.Errorf("made %d requests, want %d", requests, maxGitHubRefTries)
+	}
+}
+
+func TestHackerNews(t *testing.T) {
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/items/42" {
+			http.NotFound(w, r)
+			return
+		}
+		serveJSON(`{
+			"title": "Show HN: A thing", "author": "pg", "url": "https://example.com/thing",
+			"text": "<p>I made this.</p>", "created_at_i": 1700000000,
+			"children": [
+				{"author": "alice", "text": "<p>Nice.</p><p>Really.</p>", "children": [
+					{"author": "bob", "text": "Agreed.", "children": []}
+				]},
+				{"author": "carol", "text": "", "children": []}
+			]
+		}`)(w, r)
+	}))
+	setEndpoint(t, &hackerNewsAPI, srv.URL)
+
+	result, err := extractHackerNews(context.Background(), mustParse(t, "https://news.ycombinator.com/item?id=42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Title != "Show HN: A thing" || result.Byline != "pg" {
+		t.Errorf("title %q, byline %q", result.Title, result.Byline)
+	}
+	if result.Published == nil || !result.Published.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("published = %v", result.Published)
+	}
+	checkSections(t, result.Document, []string{
+		"|Link to example.com.|I made this.",
+		"Comments|alice wrote: Nice.|Really.|bob replied: Agreed.",
+	})
+}
+
+func TestReddit(t *testing.T) {
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/r/golang/comments/abc123.json" || !strings.HasPrefix(r.UserAgent(), "read-aloud/") {
+			http.NotFound(w, r)
+			return
+		}
+		serveJSON(`[
+			{"data": {"children": [{"kind": "t3", "data": {
+				"title": "Generics question", "author": "gopher", "is_self": true,
+				"selftext": "How do I *do* this?", "subreddit_name_prefixed": "r/golang",
+				"created_utc": 1700000000.0, "permalink": "/r/golang/comments/abc123/generics_question/"
+			}}]}},
+			{"data": {"children": [
+				{"kind": "t1", "data": {"author": "helper", "body": "Like this.", "replies": {"data": {"children": [
+					{"kind": "t1", "data": {"author": "[deleted]", "body": "[deleted]", "replies": {"data": {"children": [
+						{"kind": "t1", "data": {"author": "gopher", "body": "Thanks!", "replies": ""}}
+					]}}}}
+				]}}}},
+				{"kind": "more", "data": {}}
+			]}}
+		]`)(w, r)
+	}))
+	setEndpoint(t, &redditBase, srv.URL)
+
+	result, err := extractReddit(context.Background(), mustParse(t, "https://www.reddit.com/r/golang/comments/abc123/generics_question/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Title != "Generics question" || result.Byline != "u/gopher" || result.SiteName != "r/golang" {
+		t.Errorf("title %q, byline %q, site %q", result.Title, result.Byline, result.SiteName)
+	}
+	if result.FinalURL != "https://www.reddit.com/r/golang/comments/abc123/generics_question/" {
+		t.Errorf("final URL = %q", result.FinalURL)
+	}
+	checkSections(t, result.Document, []string{
+		"|How do I do this?",
+		"Comments|helper wrote: Like this.|gopher replied: Thanks!",
+	})
+}
+
+func TestSubstack(t *testing.T) {
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/posts/a-post" {
+			http.NotFound(w, r)
+			return
+		}
+		serveJSON(`{
+			"title": "A Post", "subtitle": "Why it matters.",
+			"body_html": "<p>First paragraph.</p><h2>Part two</h2><p>Second paragraph.</p>",
+			"post_date": "2024-03-01T12:00:00.000Z",
+			"canonical_url": "https://example.substack.com/p/a-post",
+			"publishedBylines": [{"name": "Ann"}, {"name": "Ben"}]
+		}`)(w, r)
+	}))
+	setEndpoint(t, &substackAPI, srv.URL+"/api/v1/posts")
+
+	result, err := extractSubstack(context.Background(), mustParse(t, "https://example.substack.com/p/a-post"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Title != "A Post" || result.Byline != "Ann, Ben" || result.Excerpt != "Why it matters." {
+		t.Errorf("title %q, byline %q, excerpt %q", result.Title, result.Byline, result.Excerpt)
+	}
+	if result.Published == nil || result.Published.Year() != 2024 {
+		t.Errorf("published = %v", result.Published)
+	}
+	checkSections(t, result.Document, []string{
+		"|Why it matters.|First paragraph.",
+		"Part two|Second paragraph.",
+	})
+}
+
+func TestMedium(t *testing.T) {
+	para := "This is a long enough paragraph of the story for readability to keep it as content. "
+	page := `<!DOCTYPE html><html lang="en"><head><title>A Story</title>
+		<link rel="canonical" href="https://medium.com/@someone/a-story-123"></head>
+		<body><article><h1>A Story</h1>
+		<p>Follow</p><p>5 min read</p><p>Share</p>
+		<p>` + strings.Repeat(para, 3) + `</p>
+		<p>` + strings.Repeat(para, 3) + `</p>
+		<p>1.2K</p>
+		</article></body></html>`
+	var path string
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.RequestURI()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	setEndpoint(t, &mediumBase, srv.URL)
+
+	result, err := extractMedium(context.Background(), mustParse(t, "https://medium.com/@someone/a-story-123?source=feed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/@someone/a-story-123" {
+		t.Errorf("fetched %q, want the page without its query", path)
+	}
+	if result.SiteName != "Medium" || result.FinalURL != "https://medium.com/@someone/a-story-123" {
+		t.Errorf("site %q, final URL %q", result.SiteName, result.FinalURL)
+	}
+	for _, chrome := range []string{"Follow", "5 min read", "Share", "1.2K"} {
+		for _, s := range result.Document.Sections {
+			for _, p := range s.Paragraphs {
+				if p == chrome {
+					t.Errorf("kept interface text %q", chrome)
+				}
+			}
+		}
+	}
+	if !strings.Contains(result.Text, "long enough paragraph") {
+		t.Errorf("story text missing: %q", result.Text)
+	}
+}
+
+func TestSiteFallback(t *testing.T) {
+	// A /@user/123 path matches Mastodon on any host. Without a Mastodon
+	// API there, the extractor steps aside and the page is read as a web
+	// page.
+	article := "<p>" + strings.Repeat("An ordinary blog post that happens to have a Mastodon-like path. ", 5) + "</p>"
+	srv := fixtureServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>Blog post</title></head><body><article>` +
+			article + article + `</article></body></html>`))
+	}))
+	setEndpoint(t, &mastodonBase, "http://{host}")
+
+	result, err := ExtractURL(context.Background(), srv.URL+"/@someone/123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Title != "Blog post" || !strings.Contains(result.Text, "ordinary blog post") {
+		t.Errorf("title %q, text %q", result.Title, result.Text)
+	}
+}
@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// substackAPI is a Substack publication's post API; {host} is replaced by
+// the publication's host.
+var substackAPI = "https://{host}/api/v1/posts"
+
+// substackSite reads Substack posts through the publication's API, which
+// returns the post body without the subscribe prompts around it.
+var substackSite = SiteExtractor{
+	Name: "Substack",
+	Match: func(u *url.URL) bool {
+		return hostIs(u, "substack.com", true) && substackSlug(u) != ""
+	},
+	Extract: extractSubstack,
+}
+
+// substackPost maps the parts of a post we need.
+type substackPost struct {
+	Title        string `json:"title"`
+	Subtitle     string `json:"subtitle"`
+	BodyHTML     string `json:"body_html"`
+	PostDate     string `json:"post_date"`
+	CanonicalURL string `json:"canonical_url"`
+	CoverImage   string `json:"cover_image"`
+	Bylines      []struct {
+		Name string `json:"name"`
+	} `json:"publishedBylines"`
+}
+
+// substackSlug returns the post slug of a /p/{slug} URL, or "".
+func substackSlug(u *url.URL) string {
+	slug, ok := strings.CutPrefix(u.Path, "/p/")
+	if !ok || strings.Contains(strings.TrimSuffix(slug, "/"), "/") {
+		return ""
+	}
+	return strings.TrimSuffix(slug, "/")
+}
+
+func extractSubstack(ctx context.Context, u *url.URL) (*URLResult, error) {
+	endpoint := strings.ReplaceAll(substackAPI, "{host}", u.Host) + "/" + url.PathEscape(substackSlug(u))
+	var post substackPost
+	if err := fetchJSON(ctx, endpoint, nil, &post); err != nil {
+		return nil, err
+	}
+
+	body, err := html.Parse(strings.NewReader(post.BodyHTML))
+	if err != nil {
+		return nil, fmt.Errorf("parse post body: %w", err)
+	}
+	var b docBuilder
+	b.paragraph(post.Subtitle)
+	b.addHTML(body)
+	doc := b.document(post.Title)
+
+	names := make([]string, 0, len(post.Bylines))
+	for _, by := range post.Bylines {
+		names = append(names, by.Name)
+	}
+	m := Metadata{
+		Byline:   strings.Join(names, ", "),
+		SiteName: "Substack",
+		Excerpt:  post.Subtitle,
+		Image:    post.CoverImage,
+		FinalURL: post.CanonicalURL,
+	}
+	if t, err := time.Parse(time.RFC3339, post.PostDate); err == nil {
+		m.Published = &t
+	}
+	if m.FinalURL == "" {
+		m.FinalURL = u.String()
+	}
+	return &URLResult{Title: doc.Title, Text: doc.Text(), Document: doc, Metadata: m}, nil
+}
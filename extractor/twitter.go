@@ -0,0 +1,298 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// tweetURLPattern matches X.com and Twitter.com tweet URLs and captures
+// the username (group 1) and status ID (group 2).
+var tweetURLPattern = regexp.MustCompile(
+	`^https?://(www\.)?(twitter\.com|x\.com)/(\w+)/status/(\d+)`,
+)
+
+// fxTwitterAPI is the base URL of the fxtwitter API.
+var fxTwitterAPI = "https://api.fxtwitter.com"
+
+// tweetSite reads tweets and X Articles through the fxtwitter API,
+// since they require JavaScript and cannot be fetched directly.
+var tweetSite = SiteExtractor{
+	Name: "X",
+	Match: func(u *url.URL) bool {
+		return tweetURLPattern.MatchString(u.String())
+	},
+	Extract: func(ctx context.Context, u *url.URL) (*URLResult, error) {
+		m := tweetURLPattern.FindStringSubmatch(u.String())
+		return extractTweet(ctx, m[3], m[4])
+	},
+}
+
+// fxTweetResponse maps the fxtwitter API response.
+type fxTweetResponse struct {
+	Tweet fxTweet `json:"tweet"`
+}
+
+// fxTweet maps a single status in an fxtwitter response.
+type fxTweet struct {
+	ID               string `json:"id"`
+	URL              string `json:"url"`
+	Text             string `json:"text"`
+	Lang             string `json:"lang"`
+	CreatedTimestamp int64  `json:"created_timestamp"`
+	Author           struct {
+		Name       string `json:"name"`
+		ScreenName string `json:"screen_name"`
+	} `json:"author"`
+	ReplyingTo       string     `json:"replying_to"`        // screen name
+	ReplyingToStatus string     `json:"replying_to_status"` // status ID
+	Quote            *fxTweet   `json:"quote"`
+	Media            *fxMedia   `json:"media"`
+	Article          *fxArticle `json:"article"`
+}
+
+// fxMedia maps a tweet's attached photos and videos.
+type fxMedia struct {
+	All []struct {
+		Type    string `json:"type"`
+		AltText string `json:"altText"`
+	} `json:"all"`
+}
+
+// metadata returns the tweet's author, date and link.
+func (t *fxTweet) metadata() Metadata {
+	m := Metadata{
+		Byline:   t.Author.Name,
+		SiteName: "X",
+		Language: t.Lang,
+		FinalURL: t.URL,
+	}
+	if t.Author.ScreenName != "" {
+		m.Byline = strings.TrimSpace(fmt.Sprintf("%s (@%s)", t.Author.Name, t.Author.ScreenName))
+	}
+	if t.CreatedTimestamp > 0 {
+		published := time.Unix(t.CreatedTimestamp, 0).UTC()
+		m.Published = &published
+	}
+	return m
+}
+
+// fxArticle maps the article field in an fxtwitter response.
+type fxArticle struct {
+	Title   string `json:"title"`
+	Content struct {
+		Blocks []struct {
+			Text string `json:"text"`
+			Type string `json:"type"`
+		} `json:"blocks"`
+	} `json:"content"`
+}
+
+// extractTweet uses the fxtwitter API to get tweet and article content.
+// If the tweet contains an X Article (long-form post), the full article
+// text is extracted. If it is part of a thread, the whole thread is
+// returned. Otherwise the tweet text is returned.
+func extractTweet(ctx context.Context, username, statusID string) (*URLResult, error) {
+	tweet, err := fetchTweet(ctx, username, statusID)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the tweet has an X Article, extract its full text.
+	if tweet.Article != nil && len(tweet.Article.Content.Blocks) > 0 {
+		result, err := extractXArticle(tweet.Article, tweet.Author.Name)
+		if err != nil {
+			return nil, err
+		}
+		result.Metadata = tweet.metadata()
+		return result, nil
+	}
+
+	if thread := tweetThread(ctx, tweet); len(thread) > 1 {
+		posts := make([][]string, len(thread))
+		for i, t := range thread {
+			posts[i] = t.paragraphs()
+		}
+		return postsResult(tweet.Author.ScreenName, posts, thread[0].metadata()), nil
+	}
+
+	// If the tweet text contains an external link, try to extract
+	// the article from that link.
+	if link := findExternalLink(tweet.Text); link != "" {
+		result, err := extractLinkedArticle(ctx, link)
+		if err == nil && result.Text != "" {
+			return result, nil
+		}
+	}
+
+	// Fall back to the tweet itself.
+	title := fmt.Sprintf("Tweet by @%s", tweet.Author.ScreenName)
+	var b docBuilder
+	for _, p := range tweet.paragraphs() {
+		b.paragraph(p)
+	}
+	doc := b.document(title)
+	if len(doc.Sections) == 0 {
+		doc = TextDocument(title, "(empty tweet)")
+	}
+	return &URLResult{
+		Title:    title,
+		Text:     doc.Text(),
+		Document: doc,
+		Metadata: tweet.metadata(),
+	}, nil
+}
+
+// fetchTweet reads one status from the fxtwitter API.
+func fetchTweet(ctx context.Context, username, statusID string) (*fxTweet, error) {
+	endpoint := fmt.Sprintf("%s/%s/status/%s", fxTwitterAPI,
+		url.PathEscape(username), url.PathEscape(statusID))
+	var fxResp fxTweetResponse
+	if err := fetchJSON(ctx, endpoint, nil, &fxResp); err != nil {
+		return nil, fmt.Errorf("failed to fetch tweet: %w", err)
+	}
+	return &fxResp.Tweet, nil
+}
+
+// paragraphs returns the tweet's text followed by the alt text of its
+// media and the text of a quoted tweet, each labelled for listening.
+func (t *fxTweet) paragraphs() []string {
+	var paragraphs []string
+	if text := strings.TrimSpace(t.Text); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	if t.Media != nil {
+		for _, m := range t.Media.All {
+			if alt := strings.TrimSpace(m.AltText); alt != "" {
+				kind := "Image"
+				if m.Type == "video" || m.Type == "gif" {
+					kind = "Video"
+				}
+				paragraphs = append(paragraphs, fmt.Sprintf("%s: %s", kind, alt))
+			}
+		}
+	}
+	if q := t.Quote; q != nil {
+		paragraphs = append(paragraphs, quotedPost(q.Author.Name, q.Author.ScreenName, q.paragraphs())...)
+	}
+	return paragraphs
+}
+
+// fxThreadResponse maps the fxtwitter thread endpoint, which lists the
+// author's self-replies around a status.
+type fxThreadResponse struct {
+	Thread []fxTweet `json:"thread"`
+}
+
+// tweetThread returns the thread tweet belongs to, oldest first: the
+// author's own tweets it replies to, followed by the author's replies
+// after it. Only tweets by the same author are kept, so replies from
+// others don't break into the thread. A failed lookup ends the thread
+// where it is rather than failing the extraction.
+func tweetThread(ctx context.Context, tweet *fxTweet) []*fxTweet {
+	author := tweet.Author.ScreenName
+	byID := map[string]*fxTweet{tweet.ID: tweet}
+
+	// Walk up the self-reply chain.
+	cur := tweet
+	for len(byID) < maxThreadPosts && cur.ReplyingToStatus != "" &&
+		strings.EqualFold(cur.ReplyingTo, author) && byID[cur.ReplyingToStatus] == nil {
+		parent, err := fetchTweet(ctx, author, cur.ReplyingToStatus)
+		if err != nil || parent.ID == "" {
+			break
+		}
+		byID[parent.ID] = parent
+		cur = parent
+	}
+
+	// The status API only links a tweet to its parent, so later tweets
+	// come from the thread endpoint.
+	var resp fxThreadResponse
+	endpoint := fmt.Sprintf("%s/2/thread/%s", fxTwitterAPI, url.PathEscape(tweet.ID))
+	if tweet.ID != "" && fetchJSON(ctx, endpoint, nil, &resp) == nil {
+		for i := range resp.Thread {
+			t := &resp.Thread[i]
+			if len(byID) >= maxThreadPosts {
+				break
+			}
+			if t.ID != "" && byID[t.ID] == nil && strings.EqualFold(t.Author.ScreenName, author) {
+				byID[t.ID] = t
+			}
+		}
+	}
+
+	thread := make([]*fxTweet, 0, len(byID))
+	for _, t := range byID {
+		thread = append(thread, t)
+	}
+	// Status IDs grow over time, so ordering by ID is chronological.
+	sort.Slice(thread, func(i, j int) bool {
+		a, b := thread[i].ID, thread[j].ID
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	return thread
+}
+
+// xArticleHeadings maps X Article block types to heading levels.
+var xArticleHeadings = map[string]int{
+	"header-one": 1, "header-two": 2, "header-three": 3,
+}
+
+// extractXArticle builds a readable result from an X Article's blocks.
+func extractXArticle(article *fxArticle, authorName string) (*URLResult, error) {
+	var b docBuilder
+	var paragraphs []string
+	for _, block := range article.Content.Blocks {
+		text := strings.TrimSpace(block.Text)
+		if text == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, text)
+		if level, ok := xArticleHeadings[block.Type]; ok {
+			b.heading(level, text)
+		} else {
+			b.paragraph(text)
+		}
+	}
+
+	title := article.Title
+	if title == "" {
+		title = "X Article by " + authorName
+	}
+
+	return &URLResult{
+		Title:    title,
+		Text:     strings.Join(paragraphs, "\n\n"),
+		Document: b.document(title),
+	}, nil
+}
+
+// twitterHosts are hosts that belong to Twitter/X.
+var twitterHosts = map[string]bool{
+	"twitter.com": true, "www.twitter.com": true,
+	"x.com": true, "www.x.com": true,
+	"t.co": true, "pic.twitter.com": true,
+}
+
+// findExternalLink finds the first non-Twitter URL in text.
+func findExternalLink(text string) string {
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		// Extract the host from the URL.
+		parts := strings.SplitN(
+			strings.TrimPrefix(
+				strings.TrimPrefix(link, "https://"), "http://"),
+			"/", 2)
+		host := strings.ToLower(parts[0])
+		if !twitterHosts[host] {
+			return link
+		}
+	}
+	return ""
+}
@@ -1,147 +1,66 @@
 package extractor
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
-	"time"
 
 	readability "github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
 )
 
 // URLResult holds extracted article data.
 type URLResult struct {
-	Title string `json:"title"`
-	Text  string `json:"text"`
+	Title    string    `json:"title"`
+	Text     string    `json:"text"`
+	Document *Document `json:"document,omitempty"`
+	Metadata
 }
 
-// tweetURLPattern matches X.com and Twitter.com tweet URLs and captures
-// the username (group 1) and status ID (group 2).
-var tweetURLPattern = regexp.MustCompile(
-	`^https?://(www\.)?(twitter\.com|x\.com)/(\w+)/status/(\d+)`,
-)
-
 // ExtractURL fetches the given URL and extracts readable text content.
-// For X/Twitter links it uses the fxtwitter API since tweets and
-// X Articles require JavaScript and cannot be fetched directly.
-func ExtractURL(rawURL string) (*URLResult, error) {
-	if m := tweetURLPattern.FindStringSubmatch(rawURL); m != nil {
-		username := m[3]
-		statusID := m[4]
-		return extractTweet(username, statusID)
-	}
-
-	if err := ValidateURL(rawURL); err != nil {
-		return nil, fmt.Errorf("URL rejected: %w", err)
-	}
-
-	article, err := readability.FromURL(rawURL, 30*time.Second)
+// URLs of sites with a SiteExtractor (X, Wikipedia, GitHub, ...) are
+// read through that site's API. Other web pages go through
+// go-readability; links to PDF, Word, EPUB and plain-text files go
+// through the same extractors as uploads. Language is always set, from
+// the page's declared language and the text itself. Canceling ctx
+// aborts the fetch.
+func ExtractURL(ctx context.Context, rawURL string) (*URLResult, error) {
+	result, err := extractURL(ctx, rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("extraction failed: %w", err)
+		return nil, err
 	}
-
-	return &URLResult{
-		Title: article.Title,
-		Text:  strings.TrimSpace(article.TextContent),
-	}, nil
-}
-
-// fxTweetResponse maps the fxtwitter API response.
-type fxTweetResponse struct {
-	Tweet struct {
-		Text   string `json:"text"`
-		Author struct {
-			Name       string `json:"name"`
-			ScreenName string `json:"screen_name"`
-		} `json:"author"`
-		Article *fxArticle `json:"article"`
-	} `json:"tweet"`
+	result.Language = ResolveLanguage(result.Language, result.Text)
+	return result, nil
 }
 
-// fxArticle maps the article field in an fxtwitter response.
-type fxArticle struct {
-	Title   string `json:"title"`
-	Content struct {
-		Blocks []struct {
-			Text string `json:"text"`
-		} `json:"blocks"`
-	} `json:"content"`
-}
-
-// extractTweet uses the fxtwitter API to get tweet and article content.
-// If the tweet contains an X Article (long-form post), the full article
-// text is extracted. Otherwise the tweet text is returned.
-func extractTweet(username, statusID string) (*URLResult, error) {
-	endpoint := fmt.Sprintf(
-		"https://api.fxtwitter.com/%s/status/%s", username, statusID)
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(endpoint)
+func extractURL(ctx context.Context, rawURL string) (*URLResult, error) {
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch tweet: %w", err)
+		return nil, fmt.Errorf("URL rejected: invalid URL: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(
-			"fxtwitter API returned status %d", resp.StatusCode)
-	}
-
-	var fxResp fxTweetResponse
-	if err := json.NewDecoder(resp.Body).Decode(&fxResp); err != nil {
-		return nil, fmt.Errorf("failed to parse tweet response: %w", err)
-	}
-
-	tweet := fxResp.Tweet
-
-	// If the tweet has an X Article, extract its full text.
-	if tweet.Article != nil && len(tweet.Article.Content.Blocks) > 0 {
-		return extractXArticle(tweet.Article, tweet.Author.Name)
-	}
-
-	// If the tweet text contains an external link, try to extract
-	// the article from that link.
-	if link := findExternalLink(tweet.Text); link != "" {
-		result, err := extractLinkedArticle(link)
-		if err == nil && result.Text != "" {
+	if site := matchSite(u); site != nil {
+		result, err := site.Extract(ctx, u)
+		switch {
+		case err == nil:
 			return result, nil
+		case !errors.Is(err, errNotThisSite):
+			return nil, fmt.Errorf("%s extraction failed: %w", site.Name, err)
 		}
 	}
 
-	// Fall back to the tweet text itself.
-	title := fmt.Sprintf("Tweet by @%s", tweet.Author.ScreenName)
-	text := tweet.Text
-	if text == "" {
-		text = "(empty tweet)"
-	}
-
-	return &URLResult{
-		Title: title,
-		Text:  strings.TrimSpace(text),
-	}, nil
-}
-
-// extractXArticle builds a readable result from an X Article's blocks.
-func extractXArticle(article *fxArticle, authorName string) (*URLResult, error) {
-	var paragraphs []string
-	for _, block := range article.Content.Blocks {
-		text := strings.TrimSpace(block.Text)
-		if text != "" {
-			paragraphs = append(paragraphs, text)
-		}
+	if err := ValidateURL(ctx, rawURL); err != nil {
+		return nil, fmt.Errorf("URL rejected: %w", err)
 	}
 
-	title := article.Title
-	if title == "" {
-		title = "X Article by " + authorName
+	result, err := fetchDocument(ctx, rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("extraction failed: %w", err)
 	}
 
-	return &URLResult{
-		Title: title,
-		Text:  strings.Join(paragraphs, "\n\n"),
-	}, nil
+	return result, nil
 }
 
 // linkPattern matches URLs in plain text.
@@ -152,77 +71,95 @@ func CountURLs(text string) int {
 	return len(linkPattern.FindAllStringIndex(text, -1))
 }
 
+// FirstURL returns the first URL found in the text, or "".
+func FirstURL(text string) string {
+	return linkPattern.FindString(text)
+}
+
 // ExtractFirstURL extracts readable content from the first URL found
 // in the text. The surrounding description text is discarded since it
 // typically describes the link. Only the extracted article content and
 // its title are returned.
-func ExtractFirstURL(text string) (*URLResult, error) {
+func ExtractFirstURL(ctx context.Context, text string) (*URLResult, error) {
 	loc := linkPattern.FindStringIndex(text)
 	if loc == nil {
-		return &URLResult{Text: text}, nil
+		return &URLResult{Text: text, Document: TextDocument("", text)}, nil
 	}
 
 	rawURL := text[loc[0]:loc[1]]
-	return ExtractURL(rawURL)
-}
-
-// twitterHosts are hosts that belong to Twitter/X.
-var twitterHosts = map[string]bool{
-	"twitter.com": true, "www.twitter.com": true,
-	"x.com": true, "www.x.com": true,
-	"t.co": true, "pic.twitter.com": true,
-}
-
-// findExternalLink finds the first non-Twitter URL in text.
-func findExternalLink(text string) string {
-	for _, link := range linkPattern.FindAllString(text, -1) {
-		// Extract the host from the URL.
-		parts := strings.SplitN(
-			strings.TrimPrefix(
-				strings.TrimPrefix(link, "https://"), "http://"),
-			"/", 2)
-		host := strings.ToLower(parts[0])
-		if !twitterHosts[host] {
-			return link
-		}
-	}
-	return ""
+	return ExtractURL(ctx, rawURL)
 }
 
 // extractLinkedArticle follows a URL (including redirects) and
 // extracts the readable article content using go-readability.
-func extractLinkedArticle(rawURL string) (*URLResult, error) {
-	if err := ValidateURL(rawURL); err != nil {
+func extractLinkedArticle(ctx context.Context, rawURL string) (*URLResult, error) {
+	if err := ValidateURL(ctx, rawURL); err != nil {
 		return nil, fmt.Errorf("linked URL rejected: %w", err)
 	}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if err := ValidateURL(req.URL.String()); err != nil {
-				return fmt.Errorf("redirect blocked: %w", err)
-			}
-			if len(via) >= 10 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
-	}
-	resp, err := client.Get(rawURL)
+	result, err := fetchDocument(ctx, rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to follow link: %w", err)
+		return nil, fmt.Errorf("article extraction failed: %w", err)
 	}
-	resp.Body.Close()
 
-	finalURL := resp.Request.URL.String()
+	return result, nil
+}
 
-	article, err := readability.FromURL(finalURL, 30*time.Second)
-	if err != nil {
-		return nil, fmt.Errorf("article extraction failed: %w", err)
+// articleResult converts a go-readability article into a URLResult,
+// building the document structure from the cleaned article HTML.
+func articleResult(article readability.Article) *URLResult {
+	var b docBuilder
+	if article.Node != nil {
+		b.addHTML(article.Node)
 	}
-
 	return &URLResult{
-		Title: article.Title,
-		Text:  strings.TrimSpace(article.TextContent),
-	}, nil
+		Title:    article.Title,
+		Text:     strings.TrimSpace(article.TextContent),
+		Document: b.document(article.Title),
+		Metadata: Metadata{
+			Byline:    strings.TrimSpace(article.Byline),
+			SiteName:  strings.TrimSpace(article.SiteName),
+			Excerpt:   strings.TrimSpace(article.Excerpt),
+			Language:  articleLanguage(article),
+			Published: article.PublishedTime,
+			Image:     article.Image,
+		},
+	}
+}
+
+// articleLanguage returns the lang attribute that covers most of the
+// article's text, which on a multilingual site can differ from the
+// <html lang> go-readability reports; that is the fallback.
+func articleLanguage(article readability.Article) string {
+	chars := map[string]int{}
+	var walk func(n *html.Node, lang string)
+	walk = func(n *html.Node, lang string) {
+		switch n.Type {
+		case html.TextNode:
+			chars[lang] += len(strings.TrimSpace(n.Data))
+			return
+		case html.ElementNode:
+			for _, a := range n.Attr {
+				if a.Key == "lang" && a.Namespace == "" && strings.TrimSpace(a.Val) != "" {
+					lang = strings.TrimSpace(a.Val)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, lang)
+		}
+	}
+	if article.Node != nil {
+		walk(article.Node, "")
+	}
+	best, n := "", 0
+	for lang, c := range chars {
+		if lang != "" && c > n {
+			best, n = lang, c
+		}
+	}
+	if best != "" && n > chars[""] {
+		return best
+	}
+	return article.Language
 }
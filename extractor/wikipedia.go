@@ -0,0 +1,120 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// wikipediaAPI is the MediaWiki action API; {lang} is replaced by the
+// article's language subdomain.
+var wikipediaAPI = "https://{lang}.wikipedia.org/w/api.php"
+
+// wikipediaSite reads Wikipedia articles as plain text through the
+// TextExtracts API, which leaves out infoboxes, navigation and
+// citation markers.
+var wikipediaSite = SiteExtractor{
+	Name: "Wikipedia",
+	Match: func(u *url.URL) bool {
+		return hostIs(u, "wikipedia.org", true) && wikipediaLang(u) != "" &&
+			strings.HasPrefix(u.Path, "/wiki/") && len(u.Path) > len("/wiki/")
+	},
+	Extract: extractWikipedia,
+}
+
+// wikipediaSkipSections are end matter not worth listening to.
+var wikipediaSkipSections = map[string]bool{
+	"References": true, "External links": true, "See also": true,
+	"Notes": true, "Further reading": true, "Bibliography": true,
+	"Sources": true, "Citations": true, "Footnotes": true,
+}
+
+// wikiHeading matches a section heading in a plain-text extract, e.g.
+// "== History ==".
+var wikiHeading = regexp.MustCompile(`^(={2,6})\s*(.*?)\s*={2,6}$`)
+
+// wikipediaResponse maps the parts of the query response we need.
+type wikipediaResponse struct {
+	Query struct {
+		Pages []struct {
+			Title   string `json:"title"`
+			Extract string `json:"extract"`
+			FullURL string `json:"fullurl"`
+			Missing bool   `json:"missing"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// wikipediaLang returns the language subdomain of a Wikipedia URL, or ""
+// for the portal at www.wikipedia.org.
+func wikipediaLang(u *url.URL) string {
+	lang, _, _ := strings.Cut(normalizeHost(u.Hostname()), ".")
+	if lang == "www" || lang == "wikipedia" || lang == "m" {
+		return ""
+	}
+	return lang
+}
+
+func extractWikipedia(ctx context.Context, u *url.URL) (*URLResult, error) {
+	lang := wikipediaLang(u)
+	title, err := url.PathUnescape(strings.TrimPrefix(u.Path, "/wiki/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid article title: %w", err)
+	}
+
+	q := url.Values{
+		"action":          {"query"},
+		"prop":            {"extracts|info"},
+		"inprop":          {"url"},
+		"explaintext":     {"1"},
+		"exsectionformat": {"wiki"},
+		"redirects":       {"1"},
+		"format":          {"json"},
+		"formatversion":   {"2"},
+		"titles":          {title},
+	}
+	endpoint := strings.ReplaceAll(wikipediaAPI, "{lang}", url.PathEscape(lang)) + "?" + q.Encode()
+	var resp wikipediaResponse
+	if err := fetchJSON(ctx, endpoint, nil, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Query.Pages) == 0 || resp.Query.Pages[0].Missing {
+		return nil, fmt.Errorf("article %q not found", title)
+	}
+	page := resp.Query.Pages[0]
+
+	var b docBuilder
+	skipLevel := 0 // inside a skipped section of this level
+	for _, line := range strings.Split(page.Extract, "\n") {
+		if m := wikiHeading.FindStringSubmatch(line); m != nil {
+			level := len(m[1]) - 1
+			if skipLevel > 0 && level > skipLevel {
+				continue
+			}
+			skipLevel = 0
+			if wikipediaSkipSections[m[2]] {
+				skipLevel = level
+				continue
+			}
+			b.heading(level, m[2])
+			continue
+		}
+		if skipLevel == 0 {
+			b.paragraph(line)
+		}
+	}
+
+	doc := b.document(page.Title)
+	return &URLResult{
+		Title:    page.Title,
+		Text:     doc.Text(),
+		Document: doc,
+		Metadata: Metadata{
+			SiteName: "Wikipedia",
+			Language: lang,
+			FinalURL: page.FullURL,
+		},
+	}, nil
+}
@@ -0,0 +1,76 @@
+package extractor
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxDecompressed caps the uncompressed size of any single entry read
+// from a ZIP-based document, guarding against zip bombs.
+const maxDecompressed = 50 << 20
+
+// zipArchive is a ZIP file spooled to a temp file on disk.
+type zipArchive struct {
+	*zip.Reader
+	f *os.File
+}
+
+// openZip copies r to a temp file and opens it as a ZIP archive, since
+// zip.NewReader needs a ReaderAt. The caller must call Close.
+func openZip(ctx context.Context, r io.Reader, pattern string) (*zipArchive, error) {
+	tmp, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	size, err := io.Copy(tmp, ctxReader{ctx, r})
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("write temp file: %w", err)
+	}
+
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("open zip: %w", err)
+	}
+	return &zipArchive{Reader: zr, f: tmp}, nil
+}
+
+// Close closes and removes the temp file backing the archive.
+func (a *zipArchive) Close() error {
+	err := a.f.Close()
+	os.Remove(a.f.Name())
+	return err
+}
+
+// find returns the archive entry with the given name, or nil.
+func (a *zipArchive) find(name string) *zip.File {
+	for _, zf := range a.File {
+		if zf.Name == name {
+			return zf
+		}
+	}
+	return nil
+}
+
+// openZipEntry opens an archive entry after checking its declared size against
+// maxDecompressed. The returned reader is also capped, in case the
+// header lies about the size.
+func openZipEntry(zf *zip.File) (io.ReadCloser, error) {
+	if zf.UncompressedSize64 > maxDecompressed {
+		return nil, fmt.Errorf("%s too large (%d bytes)", zf.Name, zf.UncompressedSize64)
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", zf.Name, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, maxDecompressed), rc}, nil
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"read-aloud/extractor"
+	"read-aloud/tts"
+)
+
+// maxExportChars caps the text /api/export renders in one request.
+const maxExportChars = 1 << 20
+
+// exportItem is the JSON body for exporting a saved history item. The
+// document, if sent, supplies chapter headings; otherwise the text is
+// exported as a single chapter named after the title.
+type exportItem struct {
+	Title    string              `json:"title"`
+	Text     string              `json:"text"`
+	Document *extractor.Document `json:"document"`
+	Voice    string              `json:"voice"`
+	Rate     float64             `json:"rate"`
+	Format   string              `json:"format"`
+}
+
+// Export returns the handler for POST /api/export, which renders a whole
+// document with synth and responds with a downloadable audio file.
+// Chapter markers are placed at each section heading. Long documents are
+// synthesized in chunks and concatenated server-side in a temp file;
+// audio too long for tts.Render is refused with 413.
+//
+// The request is either JSON (a history item, see exportItem) or the
+// same multipart form /api/extract accepts, with optional "voice",
+// "rate" and "format" fields. Format is "wav" (default) or "ogg".
+func Export(synth tts.Synthesizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if synth == nil {
+			jsonError(w, noSynthMessage, http.StatusServiceUnavailable)
+			return
+		}
+
+		var item exportItem
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "application/json" {
+			r.Body = http.MaxBytesReader(w, r.Body, 32<<20) // 32 MB limit
+			if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+				jsonError(w, "invalid JSON body", http.StatusBadRequest)
+				return
+			}
+		} else {
+			resp, reqErr := extractForm(r)
+			if reqErr != nil {
+				jsonError(w, reqErr.msg, reqErr.code)
+				return
+			}
+			item.Title = resp.Title
+			item.Text = resp.Text
+			item.Document = resp.Document
+			item.Voice = r.FormValue("voice")
+			item.Format = r.FormValue("format")
+			if v := r.FormValue("rate"); v != "" {
+				rate, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					jsonError(w, "invalid rate", http.StatusBadRequest)
+					return
+				}
+				item.Rate = rate
+			}
+		}
+
+		doc := item.Document
+		if doc == nil || len(doc.Sections) == 0 {
+			doc = extractor.TextDocument(item.Title, item.Text)
+		}
+		if doc.Title == "" {
+			doc.Title = item.Title
+		}
+		if len(doc.Sections) == 0 {
+			jsonError(w, "nothing to export", http.StatusBadRequest)
+			return
+		}
+		if len([]rune(doc.Text())) > maxExportChars {
+			jsonError(w, "document is too long to export", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if item.Rate == 0 {
+			item.Rate = 1
+		}
+		// Written so NaN, which fails every comparison, is rejected too.
+		if !(item.Rate >= 0.25 && item.Rate <= 4) {
+			jsonError(w, "rate must be between 0.25 and 4", http.StatusBadRequest)
+			return
+		}
+		format := strings.ToLower(item.Format)
+		if format == "" {
+			format = "wav"
+		}
+		if format != "wav" && format != "ogg" {
+			jsonError(w, "format must be wav or ogg", http.StatusBadRequest)
+			return
+		}
+
+		rendering, err := tts.Render(r.Context(), synth, documentChapters(doc), item.Voice, item.Rate)
+		if errors.Is(err, tts.ErrInvalidVoice) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, tts.ErrTooLong) {
+			jsonError(w, "document is too long to export", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			log.Printf("export synthesis error: %v", err)
+			jsonError(w, "Failed to synthesize speech.", http.StatusInternalServerError)
+			return
+		}
+		defer rendering.Close()
+
+		contentType := "audio/wav"
+		var body io.Reader
+		var size int64
+		if format == "ogg" {
+			contentType = "audio/ogg"
+			var ogg *tempFile
+			if ogg, size, err = encodeOgg(r.Context(), rendering, doc.Title); err == nil {
+				defer ogg.Close()
+				body = ogg
+			}
+		} else {
+			body, size, err = rendering.WAV()
+		}
+		if errors.Is(err, tts.ErrOggUnavailable) {
+			jsonError(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		if err != nil {
+			log.Printf("export encoding error: %v", err)
+			jsonError(w, "failed to encode audio", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.Header().Set("Content-Disposition",
+			mime.FormatMediaType("attachment", map[string]string{
+				"filename": exportFilename(doc.Title) + "." + format,
+			}))
+		if _, err := io.Copy(w, body); err != nil {
+			log.Printf("export write error: %v", err)
+		}
+	}
+}
+
+// encodeOgg encodes a rendering as Ogg Vorbis into a temp file, so an
+// encoder failure can still be reported before the response starts. It
+// returns the file, rewound, and its size; closing it removes it.
+func encodeOgg(ctx context.Context, rendering *tts.Rendering, title string) (*tempFile, int64, error) {
+	f, err := os.CreateTemp("", "read-aloud-export-*.ogg")
+	if err != nil {
+		return nil, 0, err
+	}
+	tmp := &tempFile{f}
+	if err := tts.WriteOgg(ctx, tmp, rendering, title); err != nil {
+		tmp.Close()
+		return nil, 0, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		return nil, 0, err
+	}
+	return tmp, size, nil
+}
+
+// documentChapters turns document sections into render chapters. An
+// untitled leading section is named after the document, so the export
+// always starts with a marker.
+func documentChapters(doc *extractor.Document) []tts.Chapter {
+	chapters := make([]tts.Chapter, 0, len(doc.Sections))
+	for i, s := range doc.Sections {
+		title := s.Heading
+		if i == 0 && title == "" {
+			title = doc.Title
+		}
+		text := strings.Join(s.Paragraphs, "\n")
+		if s.Heading != "" {
+			text = s.Heading + "\n" + text
+		}
+		chapters = append(chapters, tts.Chapter{Title: title, Text: text})
+	}
+	return chapters
+}
+
+// exportFilename reduces a title to a safe download file name.
+func exportFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return -1
+	}, title)
+	name = strings.Join(strings.Fields(name), " ")
+	if runes := []rune(name); len(runes) > 80 {
+		name = strings.TrimSpace(string(runes[:80]))
+	}
+	if name == "" {
+		name = "read-aloud"
+	}
+	return name
+}
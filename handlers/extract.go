@@ -1,107 +1,533 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
+	"time"
 
 	"read-aloud/extractor"
+	"read-aloud/library"
+	"read-aloud/segment"
+	"read-aloud/speechnorm"
 )
 
 // extractResponse is the JSON shape returned by /api/extract.
+// Text is the flat legacy form of the content; Document carries the same
+// content split into titled sections for chapter navigation. The
+// metadata fields (byline, published, ...) are set when the source
+// provides them, except language, which is always set: a BCP-47 tag
+// from the source's declaration and the text itself, or "und".
 type extractResponse struct {
-	Title   string `json:"title,omitempty"`
-	Text    string `json:"text"`
-	Warning string `json:"warning,omitempty"`
+	ID       string              `json:"id,omitempty"` // library item, when saved
+	Title    string              `json:"title,omitempty"`
+	Text     string              `json:"text"`
+	Document *extractor.Document `json:"document,omitempty"`
+	Warning  string              `json:"warning,omitempty"`
+	extractor.Metadata
+
+	// Segments are the sentences of Text with their UTF-16 offsets in
+	// it, for clients that speak, seek and highlight one at a time.
+	Segments []segment.Segment `json:"segments,omitempty"`
+
+	// NormalizedText and NormalizedDocument are Text and Document
+	// rewritten for speech by speechnorm, when "normalize" is set.
+	NormalizedText     string              `json:"normalizedText,omitempty"`
+	NormalizedDocument *extractor.Document `json:"normalizedDocument,omitempty"`
+
+	// Items holds the per-URL results when several links were sent at
+	// once. Title, Text and Document then combine the successful ones.
+	Items []batchItem `json:"items,omitempty"`
+
+	// kind and source describe where the content came from, in the form
+	// the library stores them.
+	kind   string
+	source string
 }
 
-// Extract handles POST /api/extract.
+// batchItem is the result for one URL of a batch extraction.
+type batchItem struct {
+	URL   string `json:"url"`
+	Error string `json:"error,omitempty"`
+	*extractResponse
+}
+
+// Batch extraction limits: how many links one request may contain, how
+// many are fetched at once, and how long each may take.
+const (
+	maxBatchURLs    = 25
+	batchWorkers    = 4
+	batchURLTimeout = 30 * time.Second
+)
+
+// requestError is an error with a user-facing message and HTTP status.
+type requestError struct {
+	msg  string
+	code int
+}
+
+func (e *requestError) Error() string { return e.msg }
+
+// Extract returns the handler for POST /api/extract.
 //
 // The request is multipart/form-data with optional fields:
-//   - "url"  — a URL to fetch and extract an article from.
-//   - "text" — plain text to read aloud directly.
-//   - "file" — an uploaded file (.txt, .md, .pdf, .docx).
+//   - "url"   — a URL to fetch and extract an article from.
+//   - "text"  — plain text to read aloud directly. Text containing
+//     several links is a batch: every link is extracted and the results
+//     are returned per URL in "items".
+//   - "file"  — an uploaded file (.txt, .md, .pdf, .docx, .doc,
+//     .epub).
+//   - "normalize" — if true, also return the text rewritten for speech
+//     (numbers, abbreviations and symbols spelled out; markdown,
+//     citations and emoji removed; URLs shortened to their domain) as
+//     "normalizedText" and "normalizedDocument".
+//   - "footnotes" — for Word documents, whether to read footnotes and
+//     endnotes, in sections after the body. On unless set false.
+//   - "headers" — for Word documents, if true, also read the page
+//     headers and footers.
+//   - "changes" — for Word documents with tracked changes, "original"
+//     reads the text as it was before them; by default every change is
+//     accepted.
+//   - "comments" — for Word documents, if true, read reviewer comments
+//     where they are anchored, as "(Comment from Name: ...)".
+//   - "queue" — if true, save the result to the library and append it
+//     to the reading queue. The response then carries the item "id"; for
+//     a batch, each item is queued in the order the links were given.
 //
-// Priority: file > url > text (if multiple are sent).
-func Extract(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+// Priority: file > url > text (if multiple are sent). lib may be nil
+// when saved data is unavailable, in which case "queue" is rejected.
+func Extract(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Check before doing the (possibly slow) extraction.
+		queue := formBool(r.FormValue("queue"))
+		if queue && lib == nil {
+			jsonError(w, "the reading queue is not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		resp, reqErr := extractForm(r)
+		if reqErr != nil {
+			jsonError(w, reqErr.msg, reqErr.code)
+			return
+		}
+		if queue {
+			if reqErr := queueResponse(lib, resp); reqErr != nil {
+				jsonError(w, reqErr.msg, reqErr.code)
+				return
+			}
+		}
+		jsonOK(w, resp)
+	}
+}
+
+// queueResponse saves the results of an extraction to the library and
+// the reading queue.
+func queueResponse(lib *library.Store, resp *extractResponse) *requestError {
+	for _, target := range queueTargets(resp) {
+		if err := saveToQueue(lib, target); err != nil {
+			log.Printf("queue extracted item: %v", err)
+			return &requestError{"failed to add the item to the queue", http.StatusInternalServerError}
+		}
+	}
+	return nil
+}
+
+// queueTargets returns what a "queue" request should save: each
+// successful article of a batch, in order, or else the response itself.
+func queueTargets(resp *extractResponse) []*extractResponse {
+	var targets []*extractResponse
+	for _, item := range resp.Items {
+		if item.extractResponse != nil {
+			targets = append(targets, item.extractResponse)
+		}
+	}
+	if len(targets) == 0 {
+		targets = append(targets, resp)
 	}
+	return targets
+}
 
+// saveToQueue adds an extraction result to the library, appends it to
+// the queue and records its ID in resp.
+func saveToQueue(lib *library.Store, resp *extractResponse) error {
+	title := resp.Title
+	if title == "" {
+		title = truncate(resp.Text, 50)
+	}
+	it := library.Item{
+		Title:  title,
+		Source: resp.source,
+		Text:   resp.Text,
+		Type:   resp.kind,
+		Byline: resp.Byline,
+	}
+	if resp.Published != nil {
+		it.Published = resp.Published.UnixMilli()
+	}
+	if resp.Language != "und" {
+		it.Language = resp.Language
+	}
+	it, err := lib.Add(it)
+	if err != nil {
+		return err
+	}
+	if err := lib.Enqueue(it.ID, -1); err != nil {
+		return err
+	}
+	resp.ID = it.ID
+	return nil
+}
+
+// formBool reports whether a form value is a "yes": 1, true, on or yes.
+func formBool(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "on", "yes":
+		return true
+	}
+	return false
+}
+
+// extractForm parses an /api/extract style form from r and runs the
+// extraction it describes. It is shared by every endpoint that accepts
+// an extract payload.
+func extractForm(r *http.Request) (*extractResponse, *requestError) {
+	in, reqErr := parseExtractForm(r)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	defer in.Close()
+	return in.extract(r.Context())
+}
+
+// extractInput is a parsed extract form, ready to be extracted.
+type extractInput struct {
+	file     io.ReadCloser // uploaded file, if any
+	filename string
+	url      string
+	text     string
+
+	normalize bool // add speech-normalized text to the response
+	docx      extractor.DOCXOptions
+}
+
+// parseExtractForm reads the extract fields from r. The caller must
+// close the returned input.
+func parseExtractForm(r *http.Request) (*extractInput, *requestError) {
 	// Parse multipart (32 MB max) — also works for plain form fields.
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
-		jsonError(w, "invalid request body", http.StatusBadRequest)
-		return
+	if err := r.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+		return nil, &requestError{"invalid request body", http.StatusBadRequest}
+	}
+
+	in := &extractInput{
+		url:       strings.TrimSpace(r.FormValue("url")),
+		text:      strings.TrimSpace(r.FormValue("text")),
+		normalize: formBool(r.FormValue("normalize")),
+		docx: extractor.DOCXOptions{
+			SkipNotes:      r.FormValue("footnotes") != "" && !formBool(r.FormValue("footnotes")),
+			HeadersFooters: formBool(r.FormValue("headers")),
+			Original:       strings.EqualFold(strings.TrimSpace(r.FormValue("changes")), "original"),
+			Comments:       formBool(r.FormValue("comments")),
+		},
+	}
+	if file, header, err := r.FormFile("file"); err == nil && header != nil {
+		in.file = file
+		in.filename = header.Filename
+	}
+	if in.file == nil && in.url == "" && in.text == "" {
+		return nil, &requestError{"provide a URL, paste text, or upload a file", http.StatusBadRequest}
+	}
+	return in, nil
+}
+
+// detach copies an uploaded file to a temp file of its own, so the
+// input outlives the request it came from. net/http removes multipart
+// temp files once the handler returns.
+func (in *extractInput) detach() error {
+	if in.file == nil {
+		return nil
+	}
+	tmp, err := os.CreateTemp("", "read-aloud-upload-*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, in.file)
+	in.file.Close()
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
 	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	in.file = &tempFile{tmp}
+	return nil
+}
+
+// Close releases the uploaded file, if any.
+func (in *extractInput) Close() error {
+	if in.file == nil {
+		return nil
+	}
+	return in.file.Close()
+}
+
+// tempFile is an *os.File that is removed when closed.
+type tempFile struct{ *os.File }
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
 
+// extract runs the extraction the input describes and adds the
+// optional parts of the response.
+func (in *extractInput) extract(ctx context.Context) (*extractResponse, *requestError) {
+	ctx = extractor.WithDOCXOptions(ctx, in.docx)
+	resp, reqErr := in.extractContent(ctx)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	resp.setLanguage()
+	resp.splitSegments()
+	if in.normalize {
+		resp.normalize()
+	}
+	return resp, nil
+}
+
+// extractContent extracts the input's content.
+// Priority: file > url > text.
+func (in *extractInput) extractContent(ctx context.Context) (*extractResponse, *requestError) {
 	// --- 1. File upload takes priority ---
-	file, header, err := r.FormFile("file")
-	if err == nil && header != nil {
-		defer file.Close()
-		text, err := extractor.ExtractFile(header.Filename, file)
+	if in.file != nil {
+		doc, err := extractor.ExtractFile(ctx, in.filename, in.file)
 		if err != nil {
 			log.Printf("file extraction error: %v", err)
-			jsonError(w, "Failed to extract text from the file.", http.StatusInternalServerError)
-			return
+			return nil, &requestError{"Failed to extract text from the file.", http.StatusInternalServerError}
+		}
+		title := doc.Title
+		if title == "" {
+			title = in.filename
 		}
-		jsonOK(w, extractResponse{
-			Title: header.Filename,
-			Text:  text,
-		})
-		return
+		return &extractResponse{
+			Title:    title,
+			Text:     doc.Text(),
+			Document: doc,
+			Metadata: doc.Metadata,
+			kind:     "file",
+			source:   in.filename,
+		}, nil
 	}
 
 	// --- 2. URL ---
-	rawURL := strings.TrimSpace(r.FormValue("url"))
-	if rawURL != "" {
-		result, err := extractor.ExtractURL(rawURL)
+	if in.url != "" {
+		result, err := extractor.ExtractURL(ctx, in.url)
 		if err != nil {
 			log.Printf("URL extraction error: %v", err)
-			jsonError(w, "Failed to extract article from the URL.", http.StatusInternalServerError)
-			return
+			return nil, urlError(err)
 		}
-		jsonOK(w, extractResponse{
-			Title: result.Title,
-			Text:  result.Text,
-		})
-		return
+		return &extractResponse{
+			Title:    result.Title,
+			Text:     result.Text,
+			Document: result.Document,
+			Metadata: result.Metadata,
+			kind:     "url",
+			source:   hostname(in.url),
+		}, nil
 	}
 
 	// --- 3. Plain text (with optional embedded URL) ---
-	text := strings.TrimSpace(r.FormValue("text"))
-	if text != "" {
-		urlCount := extractor.CountURLs(text)
-		if urlCount > 1 {
-			jsonError(w,
-				"We found more than one link in your text. "+
-					"Please paste one URL per submission so we can "+
-					"extract the right article for you.",
-				http.StatusBadRequest)
-			return
+	text := in.text
+	urlCount := extractor.CountURLs(text)
+	if urlCount > 1 {
+		return extractBatch(ctx, text)
+	}
+	if urlCount == 1 {
+		result, err := extractor.ExtractFirstURL(ctx, text)
+		if err != nil {
+			warning := "Could not extract article from the link."
+			if reason := blockedReason(err); reason != "" {
+				warning = fmt.Sprintf("Could not extract article from the link (%s).", reason)
+			}
+			return &extractResponse{
+				Text:     text,
+				Document: extractor.TextDocument("", text),
+				Warning:  warning + " Reading your original text instead.",
+				kind:     "text",
+			}, nil
 		}
-		if urlCount == 1 {
-			result, err := extractor.ExtractFirstURL(text)
-			if err != nil {
-				jsonOK(w, extractResponse{
-					Text: text,
-					Warning: "Could not extract article from the link. " +
-						"Reading your original text instead.",
-				})
-				return
+		return &extractResponse{
+			Title:    result.Title,
+			Text:     result.Text,
+			Document: result.Document,
+			Metadata: result.Metadata,
+			kind:     "url",
+			source:   hostname(extractor.FirstURL(text)),
+		}, nil
+	}
+	return &extractResponse{
+		Text:     text,
+		Document: extractor.TextDocument("", text),
+		kind:     "text",
+	}, nil
+}
+
+// extractBatch extracts every link in text concurrently and combines
+// the successful results. If none succeed, the original text is read
+// instead, as for a single failed link.
+func extractBatch(ctx context.Context, text string) (*extractResponse, *requestError) {
+	urls := extractor.AllURLs(text)
+	if len(urls) > maxBatchURLs {
+		return nil, &requestError{
+			fmt.Sprintf("We found %d links in your text. Please paste at most %d at a time.",
+				len(urls), maxBatchURLs),
+			http.StatusBadRequest}
+	}
+
+	resp := &extractResponse{kind: "url"}
+	var texts []string
+	var docs []*extractor.Document
+	failed := 0
+	for _, br := range extractor.ExtractURLs(ctx, urls, batchWorkers, batchURLTimeout) {
+		item := batchItem{URL: br.URL}
+		if br.Err != nil {
+			log.Printf("URL extraction error (%s): %v", br.URL, br.Err)
+			item.Error = urlError(br.Err).msg
+			failed++
+		} else {
+			item.extractResponse = &extractResponse{
+				Title:    br.Result.Title,
+				Text:     br.Result.Text,
+				Document: br.Result.Document,
+				Metadata: br.Result.Metadata,
+				kind:     "url",
+				source:   hostname(br.URL),
 			}
-			jsonOK(w, extractResponse{
-				Title: result.Title,
-				Text:  result.Text,
-			})
-			return
+			texts = append(texts, strings.TrimSpace(br.Result.Title+"\n"+br.Result.Text))
+			docs = append(docs, br.Result.Document)
+		}
+		resp.Items = append(resp.Items, item)
+	}
+
+	switch {
+	case failed == len(urls):
+		resp.Text = text
+		resp.Document = extractor.TextDocument("", text)
+		resp.Warning = "Could not extract articles from the links. " +
+			"Reading your original text instead."
+		resp.kind = "text"
+		return resp, nil
+	case failed > 0:
+		resp.Warning = fmt.Sprintf("Could not extract %d of %d links.", failed, len(urls))
+	}
+
+	resp.Title = fmt.Sprintf("%d articles", len(docs))
+	resp.Text = strings.Join(texts, "\n\n")
+	resp.Document = extractor.MergeDocuments(resp.Title, docs)
+	return resp, nil
+}
+
+// setLanguage detects the language of a response whose extractor set
+// none, such as pasted text or a batch, and of its batch items.
+func (resp *extractResponse) setLanguage() {
+	if resp.Language == "" {
+		resp.Language = extractor.ResolveLanguage("", resp.Text)
+	}
+	for _, item := range resp.Items {
+		if item.extractResponse != nil {
+			item.extractResponse.setLanguage()
 		}
-		jsonOK(w, extractResponse{Text: text})
-		return
 	}
+}
+
+// splitSegments splits the response's text, and that of each batch item, into
+// segments.
+func (resp *extractResponse) splitSegments() {
+	resp.Segments = segment.Split(resp.Text)
+	for _, item := range resp.Items {
+		if item.extractResponse != nil {
+			item.extractResponse.splitSegments()
+		}
+	}
+}
+
+// normalize sets the speech-normalized forms of the response's text
+// and document, and those of each batch item.
+func (resp *extractResponse) normalize() {
+	resp.NormalizedText = speechnorm.Normalize(resp.Text)
+	if resp.Document != nil {
+		resp.NormalizedDocument = normalizeDocument(resp.Document)
+	}
+	for _, item := range resp.Items {
+		if item.extractResponse != nil {
+			item.extractResponse.normalize()
+		}
+	}
+}
 
-	jsonError(w, "provide a URL, paste text, or upload a file", http.StatusBadRequest)
+// normalizeDocument returns a copy of doc with every title, heading and
+// paragraph normalized for speech. Paragraphs left empty are dropped.
+func normalizeDocument(doc *extractor.Document) *extractor.Document {
+	out := &extractor.Document{
+		Title:    speechnorm.Normalize(doc.Title),
+		Sections: make([]extractor.Section, 0, len(doc.Sections)),
+	}
+	for _, s := range doc.Sections {
+		ns := extractor.Section{
+			Heading:    speechnorm.Normalize(s.Heading),
+			Level:      s.Level,
+			Paragraphs: make([]string, 0, len(s.Paragraphs)),
+		}
+		for _, p := range s.Paragraphs {
+			if p = speechnorm.Normalize(p); p != "" {
+				ns.Paragraphs = append(ns.Paragraphs, p)
+			}
+		}
+		out.Sections = append(out.Sections, ns)
+	}
+	return out
+}
+
+// urlError returns the user-facing error for a failed URL extraction.
+// A URL refused by the egress policy gets a 403 naming the rule.
+func urlError(err error) *requestError {
+	if reason := blockedReason(err); reason != "" {
+		return &requestError{"Can't fetch this link: " + reason + ".", http.StatusForbidden}
+	}
+	return &requestError{"Failed to extract article from the URL.", http.StatusInternalServerError}
+}
+
+// blockedReason returns why the egress policy blocked a URL, or "" if
+// err is not a policy error.
+func blockedReason(err error) string {
+	var blocked *extractor.BlockedError
+	if errors.As(err, &blocked) {
+		return blocked.Error()
+	}
+	return ""
+}
+
+// hostname returns the host part of rawURL, or "" if it does not parse.
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
 }
 
 func jsonOK(w http.ResponseWriter, v interface{}) {
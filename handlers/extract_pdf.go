@@ -27,12 +27,16 @@ func ExtractPDF(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	text, err := extractor.ExtractPDF(file)
+	doc, err := extractor.ExtractPDF(r.Context(), file)
 	if err != nil {
 		jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	text := doc.Text()
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"text": text})
+	json.NewEncoder(w).Encode(map[string]string{
+		"text":     text,
+		"language": extractor.ResolveLanguage(doc.Metadata.Language, text),
+	})
 }
@@ -31,10 +31,11 @@ func ExtractURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := extractor.ExtractURL(req.URL)
+	result, err := extractor.ExtractURL(r.Context(), req.URL)
 	if err != nil {
 		log.Printf("URL extraction error: %v", err)
-		jsonError(w, "Failed to extract article from the URL.", http.StatusInternalServerError)
+		reqErr := urlError(err)
+		jsonError(w, reqErr.msg, reqErr.code)
 		return
 	}
 
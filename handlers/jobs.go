@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"read-aloud/extractor"
+	"read-aloud/jobs"
+	"read-aloud/library"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment line,
+// so proxies and browsers don't time it out during a slow fetch.
+const sseKeepAlive = 15 * time.Second
+
+// jobResponse is the JSON shape of a job.
+type jobResponse struct {
+	ID     string      `json:"id"`
+	Status string      `json:"status"` // "running", or the type of the final event
+	Last   *jobs.Event `json:"last,omitempty"`
+}
+
+// CreateJob returns the handler for POST /api/jobs. It takes the same
+// form as /api/extract, starts the extraction in the background and
+// responds 202 with the job ID, or 429 when too many are running.
+// Progress and the result are read from /api/jobs/{id}/events.
+func CreateJob(m *jobs.Manager, lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queue := formBool(r.FormValue("queue"))
+		if queue && lib == nil {
+			jsonError(w, "the reading queue is not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		in, reqErr := parseExtractForm(r)
+		if reqErr != nil {
+			jsonError(w, reqErr.msg, reqErr.code)
+			return
+		}
+		// The upload must outlive this request.
+		if err := in.detach(); err != nil {
+			log.Printf("save upload for job: %v", err)
+			jsonError(w, "failed to read the uploaded file", http.StatusInternalServerError)
+			return
+		}
+
+		job, err := m.Start(func(ctx context.Context, progress func(string, int, int)) (any, error) {
+			defer in.Close()
+			resp, reqErr := in.extract(extractor.WithProgress(ctx, progress))
+			if reqErr == nil && queue && ctx.Err() == nil {
+				reqErr = queueResponse(lib, resp)
+			}
+			if reqErr != nil {
+				return nil, reqErr
+			}
+			return resp, nil
+		})
+		if errors.Is(err, jobs.ErrTooManyJobs) {
+			in.Close()
+			jsonError(w, "too many extractions are running; try again shortly", http.StatusTooManyRequests)
+			return
+		}
+		if err != nil {
+			in.Close()
+			log.Printf("start job: %v", err)
+			jsonError(w, "failed to start the job", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Location", "/api/jobs/"+job.ID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(jobResponse{ID: job.ID, Status: "running"})
+	}
+}
+
+// GetJob returns the handler for GET /api/jobs/{id}: the job's status
+// and its latest event, which holds the result once it is done until it
+// has been streamed or a couple of minutes have passed.
+func GetJob(m *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, ok := m.Get(r.PathValue("id"))
+		if !ok {
+			jsonError(w, "job not found", http.StatusNotFound)
+			return
+		}
+		resp := jobResponse{ID: job.ID, Status: "running"}
+		if last, ok := job.Last(); ok {
+			resp.Last = &last
+			if last.Final() {
+				resp.Status = last.Type
+			}
+		}
+		jsonOK(w, resp)
+	}
+}
+
+// JobEvents returns the handler for GET /api/jobs/{id}/events. It
+// streams the job's events as Server-Sent Events, starting with those
+// already sent, and ends after the final done, error or canceled event.
+// Each event's name is its type and its data is the event as JSON. The
+// result is sent once: after that the job no longer holds it.
+func JobEvents(m *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, ok := m.Get(r.PathValue("id"))
+		if !ok {
+			jsonError(w, "job not found", http.StatusNotFound)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			jsonError(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		past, next, stop := job.Events()
+		defer stop()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		for _, e := range past {
+			writeEvent(w, e)
+		}
+		flusher.Flush()
+		if next == nil {
+			job.DropResult()
+			return
+		}
+
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case e, ok := <-next:
+				if !ok {
+					return
+				}
+				writeEvent(w, e)
+				if e.Final() {
+					flusher.Flush()
+					job.DropResult()
+					return
+				}
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			case <-r.Context().Done():
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// CancelJob returns the handler for DELETE /api/jobs/{id}. Canceling
+// stops the extraction; listeners then receive a canceled event.
+func CancelJob(m *jobs.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !m.Cancel(r.PathValue("id")) {
+			jsonError(w, "job not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// writeEvent writes e in the text/event-stream format.
+func writeEvent(w http.ResponseWriter, e jobs.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("encode job event: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+}
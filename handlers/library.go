@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"read-aloud/library"
+)
+
+// maxItemBody caps the JSON body of library item requests.
+const maxItemBody = 32 << 20
+
+// progressRequest is the body of PUT /api/library/{id}/progress.
+type progressRequest struct {
+	Progress *float64 `json:"progress"`
+	Position *int     `json:"position"`
+}
+
+// ListItems returns the handler for GET /api/library, which lists every
+// saved item, newest first.
+func ListItems(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonOK(w, lib.List())
+	}
+}
+
+// CreateItem returns the handler for POST /api/library. The body is a
+// library.Item; a client-chosen "id" is kept when it is free.
+func CreateItem(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxItemBody)
+		var it library.Item
+		if err := json.NewDecoder(r.Body).Decode(&it); err != nil {
+			jsonError(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if it.Text == "" {
+			jsonError(w, "text is required", http.StatusBadRequest)
+			return
+		}
+
+		it, err := lib.Add(it)
+		if err != nil {
+			log.Printf("library add: %v", err)
+			jsonError(w, "failed to save item", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		jsonOK(w, it)
+	}
+}
+
+// GetItem returns the handler for GET /api/library/{id}.
+func GetItem(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		it, err := lib.Get(r.PathValue("id"))
+		if err != nil {
+			libraryError(w, err)
+			return
+		}
+		jsonOK(w, it)
+	}
+}
+
+// UpdateItem returns the handler for PUT /api/library/{id}. The body is
+// a partial item; fields that are left out keep their value.
+func UpdateItem(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxItemBody)
+		var u library.Update
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			jsonError(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		it, err := lib.Update(r.PathValue("id"), u)
+		if err != nil {
+			libraryError(w, err)
+			return
+		}
+		jsonOK(w, it)
+	}
+}
+
+// UpdateProgress returns the handler for PUT /api/library/{id}/progress,
+// which records how far an item has been read:
+// {"progress": 42.5, "position": 1830}.
+func UpdateProgress(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+		var req progressRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			jsonError(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if req.Progress == nil && req.Position == nil {
+			jsonError(w, "progress or position is required", http.StatusBadRequest)
+			return
+		}
+		it, err := lib.Update(r.PathValue("id"), library.Update{
+			Progress: req.Progress,
+			Position: req.Position,
+		})
+		if err != nil {
+			libraryError(w, err)
+			return
+		}
+		jsonOK(w, it)
+	}
+}
+
+// DeleteItem returns the handler for DELETE /api/library/{id}.
+func DeleteItem(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := lib.Delete(r.PathValue("id")); err != nil {
+			libraryError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// libraryError maps a library store error to a response.
+func libraryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, library.ErrNotFound) {
+		jsonError(w, "item not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, library.ErrBadOrder) {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("library: %v", err)
+	jsonError(w, "library update failed", http.StatusInternalServerError)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"read-aloud/extractor"
+	"read-aloud/podcast"
+	"read-aloud/tts"
+)
+
+// excerptChars is how much of an article's text becomes the episode
+// description.
+const excerptChars = 280
+
+// CreateEpisode returns the handler for POST /api/episodes. It takes the
+// same form as /api/extract (plus optional "voice" and "rate"), saves
+// the result as a podcast episode and renders its audio in the
+// background. It responds 202 with the episode; the episode appears in
+// the feed once its status is "ready". When maxQueuedEpisodes are
+// already waiting to render, it responds 429 and saves nothing.
+func CreateEpisode(store *podcast.Store, synth tts.Synthesizer) http.HandlerFunc {
+	// Render one episode at a time so a burst of saves doesn't start a
+	// speech engine per article, and turn saves away once the queue is
+	// full rather than pile up waiting renders.
+	queue := make(chan episodeRender, maxQueuedEpisodes)
+	go func() {
+		for job := range queue {
+			job.render(store, synth)
+		}
+	}()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if synth == nil {
+			jsonError(w, noSynthMessage, http.StatusServiceUnavailable)
+			return
+		}
+
+		resp, reqErr := extractForm(r)
+		if reqErr != nil {
+			jsonError(w, reqErr.msg, reqErr.code)
+			return
+		}
+		rate := 1.0
+		if v := r.FormValue("rate"); v != "" {
+			var err error
+			if rate, err = strconv.ParseFloat(v, 64); err != nil || !(rate >= 0.25 && rate <= 4) {
+				jsonError(w, "rate must be between 0.25 and 4", http.StatusBadRequest)
+				return
+			}
+		}
+		voice := r.FormValue("voice")
+
+		doc := resp.Document
+		if doc == nil {
+			doc = extractor.TextDocument(resp.Title, resp.Text)
+		}
+		title := resp.Title
+		if title == "" {
+			title = truncate(resp.Text, 50)
+		}
+		if doc.Title == "" {
+			doc.Title = title
+		}
+
+		source := resp.FinalURL
+		if source == "" {
+			source = sourceURL(r)
+		}
+		excerpt := resp.Excerpt
+		if excerpt == "" {
+			excerpt = truncate(resp.Text, excerptChars)
+		}
+		ep, err := store.Create(title, source, excerpt)
+		if err != nil {
+			log.Printf("create episode: %v", err)
+			jsonError(w, "failed to save episode", http.StatusInternalServerError)
+			return
+		}
+
+		select {
+		case queue <- episodeRender{id: ep.ID, doc: doc, voice: voice, rate: rate}:
+		default:
+			store.Delete(ep.ID)
+			jsonError(w, "too many episodes are waiting to render; try again later", http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		jsonOK(w, ep)
+	}
+}
+
+// maxQueuedEpisodes is how many episodes may wait to render.
+const maxQueuedEpisodes = 16
+
+// episodeRender is an episode waiting to be rendered.
+type episodeRender struct {
+	id    string
+	doc   *extractor.Document
+	voice string
+	rate  float64
+}
+
+// render renders the episode's audio and saves it, or marks the episode
+// failed. A panic in the speech backend fails the episode instead of
+// taking down the server.
+func (e episodeRender) render(store *podcast.Store, synth tts.Synthesizer) {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("render failed: %v", r)
+			}
+		}()
+		rendering, err := tts.Render(context.Background(), synth, documentChapters(e.doc), e.voice, e.rate)
+		if err != nil {
+			return err
+		}
+		defer rendering.Close()
+		return store.SaveAudio(e.id, rendering)
+	}()
+	if err != nil {
+		log.Printf("render episode %s: %v", e.id, err)
+		store.Fail(e.id, err)
+	}
+}
+
+// ListEpisodes returns the handler for GET /api/episodes, which lists
+// every episode with its render status.
+func ListEpisodes(store *podcast.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonOK(w, store.List())
+	}
+}
+
+// DeleteEpisode returns the handler for DELETE /api/episodes/{id}.
+func DeleteEpisode(store *podcast.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ok, err := store.Delete(r.PathValue("id"))
+		if err != nil {
+			log.Printf("delete episode: %v", err)
+			jsonError(w, "failed to delete episode", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			jsonError(w, "episode not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Feed returns the handler for GET /feed.xml, an RSS 2.0 podcast feed
+// of every rendered episode. Enclosure URLs use the host the feed was
+// requested on, so a phone subscribed over the LAN address gets LAN
+// links.
+func Feed(store *podcast.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		base := requestBaseURL(r)
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		err := podcast.WriteFeed(w, podcast.Channel{
+			Title:       "Read Aloud",
+			Description: "Articles saved from Read Aloud.",
+			Link:        base + "/",
+		}, store.List(), func(id string) string {
+			return base + "/episodes/" + id + ".wav"
+		})
+		if err != nil {
+			log.Printf("write feed: %v", err)
+		}
+	}
+}
+
+// EpisodeAudio returns the handler for GET /episodes/{file}, which
+// serves a ready episode's WAV file with range support for podcast apps.
+func EpisodeAudio(store *podcast.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := strings.CutSuffix(r.PathValue("file"), ".wav")
+		ep, found := store.Get(id)
+		if !ok || !found || ep.Status != podcast.StatusReady {
+			http.NotFound(w, r)
+			return
+		}
+
+		f, err := os.Open(store.AudioPath(ep.ID))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		w.Header().Set("Content-Type", "audio/wav")
+		http.ServeContent(w, r, ep.ID+".wav", ep.Created, f)
+	}
+}
+
+// sourceURL returns the URL an extract form was about: the "url" field,
+// or the first link in "text".
+func sourceURL(r *http.Request) string {
+	if u := strings.TrimSpace(r.FormValue("url")); u != "" {
+		return u
+	}
+	return extractor.FirstURL(r.FormValue("text"))
+}
+
+// requestBaseURL returns the scheme and host the request was made to.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// truncate shortens s to at most n runes, adding an ellipsis if cut.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > n {
+		return strings.TrimSpace(string(runes[:n])) + "…"
+	}
+	return s
+}
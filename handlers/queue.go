@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"read-aloud/library"
+)
+
+type enqueueRequest struct {
+	ID       string `json:"id"`
+	Position *int   `json:"position"` // omitted: append to the end
+}
+
+type reorderRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type advanceRequest struct {
+	Finished string `json:"finished"`
+}
+
+// ListQueue returns the handler for GET /api/queue, which lists the
+// queued items in play order.
+func ListQueue(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonOK(w, lib.Queue())
+	}
+}
+
+// EnqueueItem returns the handler for POST /api/queue, which adds a
+// library item to the queue: {"id": "...", "position": 0}. An item that
+// is already queued is moved.
+func EnqueueItem(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+		var req enqueueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			jsonError(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		pos := -1
+		if req.Position != nil {
+			pos = *req.Position
+		}
+		if err := lib.Enqueue(req.ID, pos); err != nil {
+			libraryError(w, err)
+			return
+		}
+		jsonOK(w, lib.Queue())
+	}
+}
+
+// ReorderQueue returns the handler for PUT /api/queue, which sets the
+// play order: {"ids": ["b", "a", "c"]}. The IDs must be exactly the
+// queued items.
+func ReorderQueue(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		var req reorderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			jsonError(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if err := lib.Reorder(req.IDs); err != nil {
+			libraryError(w, err)
+			return
+		}
+		jsonOK(w, lib.Queue())
+	}
+}
+
+// DequeueItem returns the handler for DELETE /api/queue/{id}. The item
+// stays in the library.
+func DequeueItem(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := lib.Dequeue(r.PathValue("id")); err != nil {
+			libraryError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// NextInQueue returns the handler for GET /api/queue/next?after={id},
+// which peeks at the item queued after the given one (or the head of
+// the queue). It responds 204 when nothing is queued next.
+func NextInQueue(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		it, ok := lib.Next(r.URL.Query().Get("after"))
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		jsonOK(w, it)
+	}
+}
+
+// AdvanceQueue returns the handler for POST /api/queue/advance, which a
+// client calls when it finishes reading an item: {"finished": "..."}.
+// The finished item leaves the queue and the next one is returned so
+// the client can start it. It responds 204 when the queue is done.
+func AdvanceQueue(lib *library.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<10)
+		var req advanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			jsonError(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		it, ok, err := lib.Advance(req.Finished)
+		if err != nil {
+			log.Printf("queue advance: %v", err)
+			jsonError(w, "queue update failed", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		jsonOK(w, it)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"read-aloud/tts"
+)
+
+// maxSpeakChars caps the text /api/speak renders in one request.
+const maxSpeakChars = 50000
+
+// noSynthMessage is the error shown when no speech engine is configured.
+const noSynthMessage = "Server-side speech is not available. Install espeak-ng " +
+	"or set READ_ALOUD_TTS_COMMAND."
+
+type speakRequest struct {
+	Text  string  `json:"text"`
+	Voice string  `json:"voice"`
+	Rate  float64 `json:"rate"`
+}
+
+// Speak returns the handler for POST /api/speak, which renders the
+// posted text with synth and responds with a WAV file. A nil synth means
+// no speech engine is installed and every request gets 503.
+//
+// The body is JSON: {"text": "...", "voice": "en-us", "rate": 1.25}.
+// Voice and rate are optional.
+func Speak(synth tts.Synthesizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if synth == nil {
+			jsonError(w, noSynthMessage, http.StatusServiceUnavailable)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB limit
+		var req speakRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			jsonError(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		req.Text = strings.TrimSpace(req.Text)
+		if req.Text == "" {
+			jsonError(w, "text is required", http.StatusBadRequest)
+			return
+		}
+		if len([]rune(req.Text)) > maxSpeakChars {
+			jsonError(w, "text is too long to speak in one request",
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		if req.Rate == 0 {
+			req.Rate = 1
+		}
+		if req.Rate < 0.25 || req.Rate > 4 {
+			jsonError(w, "rate must be between 0.25 and 4", http.StatusBadRequest)
+			return
+		}
+
+		audio, err := synth.Synthesize(r.Context(), tts.Request{
+			Text:  req.Text,
+			Voice: req.Voice,
+			Rate:  req.Rate,
+		})
+		if errors.Is(err, tts.ErrInvalidVoice) {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("speech synthesis error: %v", err)
+			jsonError(w, "Failed to synthesize speech.", http.StatusInternalServerError)
+			return
+		}
+
+		writeWAV(w, audio)
+	}
+}
+
+// writeWAV sends audio as a WAV response.
+func writeWAV(w http.ResponseWriter, audio *tts.Audio) {
+	var buf bytes.Buffer
+	if err := tts.WriteWAV(&buf, audio); err != nil {
+		jsonError(w, "failed to encode audio", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "audio/wav")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
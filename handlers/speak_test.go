@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"read-aloud/tts"
+)
+
+func postSpeak(t *testing.T, synth tts.Synthesizer, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/speak", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	Speak(synth).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSpeak(t *testing.T) {
+	rec := postSpeak(t, &tts.Tone{}, `{"text": "hello there world", "rate": 2}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "audio/wav" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	audio, err := tts.ReadWAV(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := (&tts.Tone{}).Synthesize(t.Context(), tts.Request{Text: "hello there world", Rate: 2})
+	if !bytes.Equal(audio.PCM, want.PCM) {
+		t.Error("response audio differs from the Tone rendering")
+	}
+}
+
+func TestSpeakErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		synth tts.Synthesizer
+		body  string
+		code  int
+	}{
+		{"no synthesizer", nil, `{"text": "hi"}`, http.StatusServiceUnavailable},
+		{"bad JSON", &tts.Tone{}, `{"text":`, http.StatusBadRequest},
+		{"no text", &tts.Tone{}, `{"text": "  "}`, http.StatusBadRequest},
+		{"rate too high", &tts.Tone{}, `{"text": "hi", "rate": 5}`, http.StatusBadRequest},
+		{"too long", &tts.Tone{}, `{"text": "` + strings.Repeat("a", maxSpeakChars+1) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postSpeak(t, tt.synth, tt.body)
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+			var body struct{ Error string }
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Error == "" {
+				t.Errorf("no JSON error in response")
+			}
+		})
+	}
+}
+
+func TestSpeakMethod(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Speak(&tts.Tone{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/speak", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
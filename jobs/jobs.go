@@ -0,0 +1,240 @@
+// Package jobs runs extractions in the background and lets clients
+// follow their progress as a stream of events.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event types. Done, Error and Canceled are final: a job sends exactly
+// one of them, as its last event.
+const (
+	EventProgress = "progress"
+	EventDone     = "done"
+	EventError    = "error"
+	EventCanceled = "canceled"
+)
+
+// keepFinished is how long a finished job stays available to clients
+// that connect late. Its result, which holds the whole article, is
+// only kept for keepResult, or until a client has received it.
+const (
+	keepFinished = 10 * time.Minute
+	keepResult   = 2 * time.Minute
+)
+
+// maxRunning is how many jobs may run at once.
+const maxRunning = 8
+
+// ErrTooManyJobs is returned by Start when maxRunning jobs are running.
+var ErrTooManyJobs = errors.New("too many jobs running")
+
+// Event is one step of a job.
+type Event struct {
+	Type    string `json:"type"`
+	Stage   string `json:"stage,omitempty"`
+	Done    int    `json:"done,omitempty"`
+	Total   int    `json:"total,omitempty"`
+	Message string `json:"message,omitempty"`
+	Result  any    `json:"result,omitempty"`
+}
+
+// Final reports whether e is the last event of its job.
+func (e Event) Final() bool {
+	return e.Type == EventDone || e.Type == EventError || e.Type == EventCanceled
+}
+
+// Func is the work of a job. It should report progress through
+// progress and stop promptly when ctx is canceled.
+type Func func(ctx context.Context, progress func(stage string, done, total int)) (any, error)
+
+// Job is a running or finished job.
+type Job struct {
+	ID      string
+	Created time.Time
+
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	events []Event
+	subs   map[chan Event]struct{}
+}
+
+// Manager keeps the jobs of one server. It is safe for concurrent use.
+type Manager struct {
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	running int
+}
+
+// NewManager returns an empty Manager.
+func NewManager() *Manager {
+	return &Manager{jobs: make(map[string]*Job)}
+}
+
+// Start runs fn in the background and returns its job, or
+// ErrTooManyJobs if maxRunning jobs are already running.
+func (m *Manager) Start(fn Func) (*Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &Job{
+		ID:      id,
+		Created: time.Now(),
+		cancel:  cancel,
+		subs:    make(map[chan Event]struct{}),
+	}
+
+	m.mu.Lock()
+	if m.running >= maxRunning {
+		m.mu.Unlock()
+		cancel()
+		return nil, ErrTooManyJobs
+	}
+	m.running++
+	m.jobs[id] = j
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := run(ctx, fn, func(stage string, done, total int) {
+			j.publish(Event{Type: EventProgress, Stage: stage, Done: done, Total: total})
+		})
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			j.publish(Event{Type: EventCanceled, Message: "canceled"})
+		case err != nil:
+			j.publish(Event{Type: EventError, Message: err.Error()})
+		default:
+			j.publish(Event{Type: EventDone, Result: result})
+		}
+		m.mu.Lock()
+		m.running--
+		m.mu.Unlock()
+
+		time.AfterFunc(keepResult, j.DropResult)
+		time.AfterFunc(keepFinished, func() {
+			m.mu.Lock()
+			delete(m.jobs, id)
+			m.mu.Unlock()
+		})
+	}()
+	return j, nil
+}
+
+// run calls fn, turning a panic into an error. net/http only recovers
+// handler goroutines, so without this a parser panicking on a malformed
+// upload would take down the whole server.
+func run(ctx context.Context, fn Func, progress func(stage string, done, total int)) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("extraction failed: %v", r)
+		}
+	}()
+	return fn(ctx, progress)
+}
+
+// Get returns the job with the given ID.
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	return j, ok
+}
+
+// Cancel stops the job with the given ID. It reports false if there is
+// no such job. Canceling a finished job does nothing.
+func (m *Manager) Cancel(id string) bool {
+	j, ok := m.Get(id)
+	if ok {
+		j.cancel()
+	}
+	return ok
+}
+
+// Events returns the events so far and, unless the job has finished, a
+// channel that receives the ones after them. The channel is closed after
+// the final event; call stop to unsubscribe early.
+func (j *Job) Events() (past []Event, next <-chan Event, stop func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	past = append([]Event(nil), j.events...)
+	if n := len(past); n > 0 && past[n-1].Final() {
+		return past, nil, func() {}
+	}
+	ch := make(chan Event, 16)
+	j.subs[ch] = struct{}{}
+	stop = func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if _, ok := j.subs[ch]; ok {
+			delete(j.subs, ch)
+			close(ch)
+		}
+	}
+	return past, ch, stop
+}
+
+// Last returns the most recent event, or false if there is none yet.
+func (j *Job) Last() (Event, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.events) == 0 {
+		return Event{}, false
+	}
+	return j.events[len(j.events)-1], true
+}
+
+// DropResult forgets the result of a finished job, once a client has
+// it. The job still reports that it is done.
+func (j *Job) DropResult() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if n := len(j.events); n > 0 {
+		j.events[n-1].Result = nil
+	}
+}
+
+// publish records e and sends it to subscribers. Progress events are
+// dropped for subscribers that fall behind; the final event always
+// arrives, since it is sent just before the channel is closed.
+func (j *Job) publish(e Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.events = append(j.events, e)
+	for ch := range j.subs {
+		if e.Final() {
+			// Make room so the final event is never lost.
+			select {
+			case <-ch:
+			default:
+			}
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+		if e.Final() {
+			delete(j.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
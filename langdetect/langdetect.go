@@ -0,0 +1,293 @@
+// Package langdetect guesses the language of a text offline. Scripts
+// used by a single language (Greek, Hangul, Thai, ...) decide it
+// outright; Latin and Cyrillic text is scored against character
+// trigram profiles with a naive Bayes model. Results are BCP-47
+// primary language subtags such as "en" or "zh".
+package langdetect
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Limits on the text examined: how much of it is read, and how many
+// letters a guess needs.
+const (
+	maxSample  = 20000 // bytes
+	minLetters = 20
+)
+
+// minMargin is how much better per trigram, in log probability, the
+// best Latin or Cyrillic profile must score than the runner-up for a
+// confident guess.
+const minMargin = 0.04
+
+// Detect returns the language of text and whether the guess is
+// confident. Short or mixed texts may return a guess that is not.
+func Detect(text string) (lang string, confident bool) {
+	if len(text) > maxSample {
+		text = text[:maxSample]
+	}
+	script, letters, counts := dominantScript(text)
+	if letters < minLetters {
+		return "", false
+	}
+	switch script {
+	case scriptLatin, scriptCyrillic:
+		return scoreTrigrams(script, text)
+	case scriptHan:
+		// Japanese mixes kanji with kana; Chinese has no kana.
+		if counts[scriptKana]*10 > counts[scriptHan]+counts[scriptKana] {
+			return "ja", true
+		}
+		return "zh", true
+	case scriptArabic:
+		return arabicLanguage(text), true
+	}
+	if lang, ok := scriptLanguages[script]; ok {
+		return lang, true
+	}
+	return "", false
+}
+
+// Scripts that decide or narrow down the language.
+const (
+	scriptOther = iota
+	scriptLatin
+	scriptCyrillic
+	scriptGreek
+	scriptArabic
+	scriptHebrew
+	scriptHan
+	scriptKana
+	scriptHangul
+	scriptThai
+	scriptDevanagari
+	scriptBengali
+	scriptTamil
+	scriptTelugu
+	scriptGujarati
+	scriptGurmukhi
+	scriptKannada
+	scriptMalayalam
+	scriptGeorgian
+	scriptArmenian
+	scriptEthiopic
+	scriptKhmer
+	scriptLao
+	scriptMyanmar
+	scriptSinhala
+	numScripts
+)
+
+var scriptTables = []struct {
+	script int
+	table  *unicode.RangeTable
+}{
+	{scriptLatin, unicode.Latin},
+	{scriptCyrillic, unicode.Cyrillic},
+	{scriptGreek, unicode.Greek},
+	{scriptArabic, unicode.Arabic},
+	{scriptHebrew, unicode.Hebrew},
+	{scriptHan, unicode.Han},
+	{scriptKana, unicode.Hiragana},
+	{scriptKana, unicode.Katakana},
+	{scriptHangul, unicode.Hangul},
+	{scriptThai, unicode.Thai},
+	{scriptDevanagari, unicode.Devanagari},
+	{scriptBengali, unicode.Bengali},
+	{scriptTamil, unicode.Tamil},
+	{scriptTelugu, unicode.Telugu},
+	{scriptGujarati, unicode.Gujarati},
+	{scriptGurmukhi, unicode.Gurmukhi},
+	{scriptKannada, unicode.Kannada},
+	{scriptMalayalam, unicode.Malayalam},
+	{scriptGeorgian, unicode.Georgian},
+	{scriptArmenian, unicode.Armenian},
+	{scriptEthiopic, unicode.Ethiopic},
+	{scriptKhmer, unicode.Khmer},
+	{scriptLao, unicode.Lao},
+	{scriptMyanmar, unicode.Myanmar},
+	{scriptSinhala, unicode.Sinhala},
+}
+
+// scriptLanguages are the languages decided by their script alone.
+var scriptLanguages = map[int]string{
+	scriptGreek: "el", scriptHebrew: "he", scriptHangul: "ko",
+	scriptThai: "th", scriptDevanagari: "hi", scriptBengali: "bn",
+	scriptTamil: "ta", scriptTelugu: "te", scriptGujarati: "gu",
+	scriptGurmukhi: "pa", scriptKannada: "kn", scriptMalayalam: "ml",
+	scriptGeorgian: "ka", scriptArmenian: "hy", scriptEthiopic: "am",
+	scriptKhmer: "km", scriptLao: "lo", scriptMyanmar: "my",
+	scriptSinhala: "si",
+}
+
+// dominantScript counts the letters of each script in text and returns
+// the most common one. Kana count as Han, since Japanese mixes both.
+func dominantScript(text string) (script, letters int, counts [numScripts]int) {
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, st := range scriptTables {
+			if unicode.Is(st.table, r) {
+				counts[st.script]++
+				break
+			}
+		}
+	}
+	best, bestN := scriptOther, 0
+	for s := scriptLatin; s < numScripts; s++ {
+		n := counts[s]
+		switch s {
+		case scriptKana:
+			continue
+		case scriptHan:
+			n += counts[scriptKana]
+		}
+		if n > bestN {
+			best, bestN = s, n
+		}
+	}
+	return best, letters, counts
+}
+
+// arabicLanguage tells Arabic, Persian and Urdu apart by the letters
+// each adds to or drops from the Arabic alphabet.
+func arabicLanguage(text string) string {
+	switch {
+	case strings.ContainsAny(text, "ےںٹڈڑ"):
+		return "ur"
+	case strings.ContainsAny(text, "پچژگ") && !strings.ContainsAny(text, "ةى"):
+		return "fa"
+	}
+	return "ar"
+}
+
+// profile is a language's trigram model.
+type profile struct {
+	lang   string
+	script int
+	logP   map[string]float64
+	unseen float64 // log probability of a trigram not in the sample
+}
+
+var (
+	profilesOnce sync.Once
+	profiles     []profile
+)
+
+// vocabulary is the assumed number of distinct trigrams, for add-one
+// smoothing.
+const vocabulary = 4000
+
+// loadProfiles builds the trigram models from the sample texts.
+func loadProfiles() {
+	for _, s := range samples {
+		counts := trigrams(s.text)
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		p := profile{
+			lang:   s.lang,
+			script: s.script,
+			logP:   make(map[string]float64, len(counts)),
+			unseen: math.Log(1 / float64(total+vocabulary)),
+		}
+		for t, n := range counts {
+			p.logP[t] = math.Log(float64(n+1) / float64(total+vocabulary))
+		}
+		profiles = append(profiles, p)
+	}
+}
+
+// scoreTrigrams picks the profile of the given script under which text
+// is most likely.
+func scoreTrigrams(script int, text string) (string, bool) {
+	profilesOnce.Do(loadProfiles)
+	counts := trigrams(text)
+	n := 0
+	for _, c := range counts {
+		n += c
+	}
+	if n == 0 {
+		return "", false
+	}
+
+	hints := distinctiveCounts(text)
+	best, second := math.Inf(-1), math.Inf(-1)
+	lang := ""
+	for _, p := range profiles {
+		if p.script != script {
+			continue
+		}
+		score := 0.0
+		for t, c := range counts {
+			lp, ok := p.logP[t]
+			if !ok {
+				lp = p.unseen
+			}
+			score += float64(c) * lp
+		}
+		score = (score + distinctiveWeight*float64(hints[p.lang])) / float64(n)
+		switch {
+		case score > best:
+			best, second, lang = score, best, p.lang
+		case score > second:
+			second = score
+		}
+	}
+	return lang, n >= minLetters && best-second >= minMargin
+}
+
+// distinctive are letters that, among the profiled languages, only one
+// writes. Each occurrence adds distinctiveWeight to that language's
+// score, which separates close neighbours (Czech and Croatian, Turkish
+// and Indonesian) that the small trigram samples alone barely tell
+// apart, while a lone loanword in a long text hardly counts.
+var distinctive = map[rune]string{
+	'ř': "cs", 'ů': "cs", 'ě': "cs",
+	'ľ': "sk", 'ĺ': "sk", 'ŕ': "sk",
+	'ą': "pl", 'ę': "pl", 'ł': "pl", 'ś': "pl", 'ź': "pl", 'ż': "pl", 'ń': "pl",
+	'ő': "hu", 'ű': "hu",
+	'ı': "tr", 'ğ': "tr", 'ş': "tr",
+	'ș': "ro", 'ț': "ro",
+	'ñ': "es",
+	'õ': "pt",
+	'ß': "de",
+	'œ': "fr",
+	'ć': "hr", 'đ': "hr",
+	'ơ': "vi", 'ư': "vi", 'ạ': "vi", 'ả': "vi", 'ế': "vi", 'ệ': "vi", 'ộ': "vi",
+	'ы': "ru", 'э': "ru", 'ё': "ru",
+	'і': "uk", 'ї': "uk", 'є': "uk", 'ґ': "uk",
+	'ђ': "sr", 'ј': "sr", 'љ': "sr", 'њ': "sr", 'ћ': "sr", 'џ': "sr",
+}
+
+const distinctiveWeight = 5.0
+
+func distinctiveCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, r := range strings.ToLower(text) {
+		if lang, ok := distinctive[r]; ok {
+			counts[lang]++
+		}
+	}
+	return counts
+}
+
+// trigrams counts the letter trigrams of text, lower-cased, with each
+// word padded by a space on either side so word starts and ends count.
+func trigrams(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		runes := []rune(" " + strings.ToLower(word) + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,37 @@
+package langdetect
+
+// samples are the texts the trigram profiles are built from: the first
+// article of the Universal Declaration of Human Rights followed by a few
+// everyday sentences, so both formal and plain prose are represented.
+var samples = []struct {
+	lang   string
+	script int
+	text   string
+}{
+	{"en", scriptLatin, `All human beings are born free and equal in dignity and rights. They are endowed with reason and conscience and should act towards one another in a spirit of brotherhood. The weather was nice this morning, so we went for a walk in the park with the children. What do you think about the new book that she has written? It is one of the most interesting stories I have read in years, and I would like to know more about it. The company said on Monday that its profits had fallen because of higher costs, but it expects the situation to improve next year when the new factory opens. The train to the city leaves every hour from the old station near the river. Many people work from home now and only go to the office two or three days a week. Doctors say that children should sleep at least nine hours and spend less time in front of screens. We could not find a table at the restaurant, so we cooked dinner at home and watched a film.`},
+	{"fr", scriptLatin, `Tous les êtres humains naissent libres et égaux en dignité et en droits. Ils sont doués de raison et de conscience et doivent agir les uns envers les autres dans un esprit de fraternité. Il faisait beau ce matin, alors nous sommes allés nous promener dans le parc avec les enfants. Que pensez-vous du nouveau livre qu'elle a écrit ? C'est l'une des histoires les plus intéressantes que j'ai lues depuis des années, et je voudrais en savoir plus. L'entreprise a annoncé lundi que ses bénéfices avaient baissé à cause de la hausse des coûts, mais elle s'attend à ce que la situation s'améliore l'année prochaine avec l'ouverture de la nouvelle usine. Le train pour la ville part toutes les heures de la vieille gare près de la rivière. Beaucoup de gens travaillent maintenant à la maison et ne vont au bureau que deux ou trois jours par semaine. Les médecins disent que les enfants devraient dormir au moins neuf heures et passer moins de temps devant les écrans. Nous n'avons pas trouvé de table au restaurant, alors nous avons préparé le dîner chez nous et regardé un film.`},
+	{"de", scriptLatin, `Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie sind mit Vernunft und Gewissen begabt und sollen einander im Geist der Brüderlichkeit begegnen. Das Wetter war heute Morgen schön, deshalb sind wir mit den Kindern im Park spazieren gegangen. Was denken Sie über das neue Buch, das sie geschrieben hat? Es ist eine der interessantesten Geschichten, die ich seit Jahren gelesen habe, und ich möchte mehr darüber wissen. Das Unternehmen teilte am Montag mit, dass seine Gewinne wegen höherer Kosten gesunken seien, erwartet aber eine Verbesserung im nächsten Jahr, wenn die neue Fabrik eröffnet wird. Der Zug in die Stadt fährt jede Stunde vom alten Bahnhof am Fluss ab. Viele Leute arbeiten jetzt von zu Hause aus und gehen nur noch zwei oder drei Tage in der Woche ins Büro. Ärzte sagen, dass Kinder mindestens neun Stunden schlafen und weniger Zeit vor Bildschirmen verbringen sollten. Wir haben im Restaurant keinen Tisch bekommen, also haben wir zu Hause gekocht und einen Film angeschaut.`},
+	{"es", scriptLatin, `Todos los seres humanos nacen libres e iguales en dignidad y derechos y, dotados como están de razón y conciencia, deben comportarse fraternalmente los unos con los otros. Hacía buen tiempo esta mañana, así que fuimos a pasear por el parque con los niños. ¿Qué piensa usted del nuevo libro que ella ha escrito? Es una de las historias más interesantes que he leído en años, y me gustaría saber más sobre ella. La empresa dijo el lunes que sus beneficios habían caído debido a los costes más altos, pero espera que la situación mejore el próximo año cuando abra la nueva fábrica. El tren a la ciudad sale cada hora de la vieja estación junto al río. Mucha gente trabaja ahora desde casa y solo va a la oficina dos o tres días a la semana. Los médicos dicen que los niños deberían dormir al menos nueve horas y pasar menos tiempo delante de las pantallas. No encontramos mesa en el restaurante, así que cocinamos la cena en casa y vimos una película.`},
+	{"it", scriptLatin, `Tutti gli esseri umani nascono liberi ed eguali in dignità e diritti. Essi sono dotati di ragione e di coscienza e devono agire gli uni verso gli altri in spirito di fratellanza. Stamattina il tempo era bello, quindi siamo andati a fare una passeggiata nel parco con i bambini. Che cosa pensa del nuovo libro che lei ha scritto? È una delle storie più interessanti che abbia letto da anni, e vorrei saperne di più. L'azienda ha detto lunedì che i suoi profitti sono diminuiti a causa dei costi più alti, ma si aspetta che la situazione migliori l'anno prossimo quando aprirà la nuova fabbrica. Il treno per la città parte ogni ora dalla vecchia stazione vicino al fiume. Molte persone ora lavorano da casa e vanno in ufficio solo due o tre giorni alla settimana. I medici dicono che i bambini dovrebbero dormire almeno nove ore e passare meno tempo davanti agli schermi. Non abbiamo trovato un tavolo al ristorante, quindi abbiamo cucinato la cena a casa e abbiamo guardato un film.`},
+	{"pt", scriptLatin, `Todos os seres humanos nascem livres e iguais em dignidade e em direitos. Dotados de razão e de consciência, devem agir uns para com os outros em espírito de fraternidade. O tempo estava bom esta manhã, então fomos passear no parque com as crianças. O que você acha do novo livro que ela escreveu? É uma das histórias mais interessantes que li em anos, e eu gostaria de saber mais sobre isso. A empresa disse na segunda-feira que os seus lucros caíram por causa dos custos mais altos, mas espera que a situação melhore no próximo ano, quando a nova fábrica for inaugurada. O comboio para a cidade parte de hora a hora da velha estação perto do rio. Muitas pessoas trabalham agora em casa e só vão ao escritório dois ou três dias por semana. Os médicos dizem que as crianças deviam dormir pelo menos nove horas e passar menos tempo em frente aos ecrãs. Não conseguimos encontrar uma mesa no restaurante, por isso fizemos o jantar em casa e vimos um filme.`},
+	{"ca", scriptLatin, `Tots els éssers humans neixen lliures i iguals en dignitat i en drets. Són dotats de raó i de consciència, i han de comportar-se fraternalment els uns amb els altres. Aquest matí feia bon temps, així que vam anar a passejar pel parc amb els nens. Què en pensa del nou llibre que ella ha escrit? És una de les històries més interessants que he llegit en anys, i m'agradaria saber-ne més. L'empresa va dir dilluns que els seus beneficis havien baixat a causa dels costos més alts, però espera que la situació millori l'any vinent quan obri la nova fàbrica. El tren cap a la ciutat surt cada hora de la vella estació prop del riu. Molta gent treballa ara des de casa i només va a l'oficina dos o tres dies a la setmana. Els metges diuen que els nens haurien de dormir almenys nou hores i passar menys temps davant de les pantalles. No vam trobar taula al restaurant, així que vam fer el sopar a casa i vam mirar una pel·lícula.`},
+	{"nl", scriptLatin, `Alle mensen worden vrij en gelijk in waardigheid en rechten geboren. Zij zijn begiftigd met verstand en geweten, en behoren zich jegens elkander in een geest van broederschap te gedragen. Het weer was vanochtend mooi, dus zijn we met de kinderen in het park gaan wandelen. Wat vindt u van het nieuwe boek dat zij heeft geschreven? Het is een van de interessantste verhalen die ik in jaren heb gelezen, en ik zou er graag meer over willen weten. Het bedrijf zei maandag dat de winst was gedaald door hogere kosten, maar het verwacht dat de situatie volgend jaar verbetert wanneer de nieuwe fabriek opengaat. De trein naar de stad vertrekt elk uur van het oude station bij de rivier. Veel mensen werken nu thuis en gaan maar twee of drie dagen per week naar kantoor. Artsen zeggen dat kinderen minstens negen uur moeten slapen en minder tijd achter schermen moeten doorbrengen. We konden geen tafel vinden in het restaurant, dus hebben we thuis gekookt en een film gekeken.`},
+	{"sv", scriptLatin, `Alla människor är födda fria och lika i värde och rättigheter. De är utrustade med förnuft och samvete och bör handla gentemot varandra i en anda av broderskap. Vädret var fint i morse, så vi tog en promenad i parken med barnen. Vad tycker du om den nya boken som hon har skrivit? Det är en av de mest intressanta berättelser jag har läst på flera år, och jag skulle vilja veta mer om den. Företaget meddelade på måndagen att vinsten hade minskat på grund av högre kostnader, men det väntar sig att läget förbättras nästa år när den nya fabriken öppnar. Tåget till staden går varje timme från den gamla stationen vid floden. Många arbetar nu hemifrån och går bara till kontoret två eller tre dagar i veckan. Läkare säger att barn borde sova minst nio timmar och tillbringa mindre tid framför skärmar. Vi fick inget bord på restaurangen, så vi lagade middag hemma och tittade på en film.`},
+	{"da", scriptLatin, `Alle mennesker er født frie og lige i værdighed og rettigheder. De er udstyret med fornuft og samvittighed, og de bør handle mod hverandre i en broderskabets ånd. Vejret var dejligt i morges, så vi gik en tur i parken med børnene. Hvad synes du om den nye bog, som hun har skrevet? Det er en af de mest interessante historier, jeg har læst i mange år, og jeg vil gerne vide mere om den. Virksomheden oplyste mandag, at overskuddet var faldet på grund af højere omkostninger, men den forventer, at situationen bliver bedre næste år, når den nye fabrik åbner. Toget til byen kører hver time fra den gamle station ved åen. Mange mennesker arbejder nu hjemmefra og tager kun på kontoret to eller tre dage om ugen. Lægerne siger, at børn bør sove mindst ni timer og bruge mindre tid foran skærmen. Vi kunne ikke få et bord på restauranten, så vi lavede aftensmad derhjemme og så en film. Hvad skal vi lave i weekenden, og hvorfor er det så svært at beslutte sig?`},
+	{"nb", scriptLatin, `Alle mennesker er født frie og med samme menneskeverd og menneskerettigheter. De er utstyrt med fornuft og samvittighet og bør handle mot hverandre i brorskapets ånd. Været var fint i morges, så vi gikk en tur i parken med barna. Hva synes du om den nye boken som hun har skrevet? Det er en av de mest interessante historiene jeg har lest på mange år, og jeg vil gjerne vite mer om den. Selskapet sa mandag at overskuddet hadde gått ned på grunn av høyere kostnader, men det venter at situasjonen blir bedre neste år når den nye fabrikken åpner. Toget til byen går hver time fra den gamle stasjonen ved elva. Mange jobber nå hjemmefra og drar bare på kontoret to eller tre dager i uka. Legene sier at barn bør sove minst ni timer og bruke mindre tid foran skjermen. Vi fikk ikke bord på restauranten, så vi lagde middag hjemme og så en film. Hva skal vi gjøre i helga, og hvorfor er det så vanskelig å bestemme seg? Regjeringen og Stortinget skal behandle saken etter sommeren.`},
+	{"fi", scriptLatin, `Kaikki ihmiset syntyvät vapaina ja tasavertaisina arvoltaan ja oikeuksiltaan. Heille on annettu järki ja omatunto, ja heidän on toimittava toisiaan kohtaan veljeyden hengessä. Sää oli kaunis tänä aamuna, joten kävimme lasten kanssa kävelyllä puistossa. Mitä mieltä olet uudesta kirjasta, jonka hän on kirjoittanut? Se on yksi kiinnostavimmista tarinoista, joita olen lukenut vuosiin, ja haluaisin tietää siitä enemmän. Yhtiö kertoi maanantaina, että sen voitot olivat laskeneet korkeampien kustannusten vuoksi, mutta se odottaa tilanteen paranevan ensi vuonna, kun uusi tehdas avataan. Juna kaupunkiin lähtee joka tunti vanhalta asemalta joen rannasta. Monet ihmiset tekevät nyt töitä kotoa käsin ja käyvät toimistolla vain kahtena tai kolmena päivänä viikossa. Lääkäreiden mukaan lasten pitäisi nukkua vähintään yhdeksän tuntia ja viettää vähemmän aikaa näyttöjen ääressä. Emme löytäneet pöytää ravintolasta, joten teimme illallisen kotona ja katsoimme elokuvan.`},
+	{"pl", scriptLatin, `Wszyscy ludzie rodzą się wolni i równi pod względem swej godności i swych praw. Są oni obdarzeni rozumem i sumieniem i powinni postępować wobec innych w duchu braterstwa. Dziś rano była ładna pogoda, więc poszliśmy z dziećmi na spacer do parku. Co pan myśli o nowej książce, którą ona napisała? To jedna z najciekawszych historii, jakie przeczytałem od lat, i chciałbym wiedzieć o niej więcej. Firma poinformowała w poniedziałek, że jej zyski spadły z powodu wyższych kosztów, ale spodziewa się, że sytuacja poprawi się w przyszłym roku, kiedy zostanie otwarta nowa fabryka. Pociąg do miasta odjeżdża co godzinę ze starego dworca nad rzeką. Wiele osób pracuje teraz z domu i chodzi do biura tylko dwa lub trzy dni w tygodniu. Lekarze mówią, że dzieci powinny spać co najmniej dziewięć godzin i spędzać mniej czasu przed ekranami. Nie znaleźliśmy stolika w restauracji, więc ugotowaliśmy kolację w domu i obejrzeliśmy film.`},
+	{"cs", scriptLatin, `Všichni lidé rodí se svobodní a sobě rovní co do důstojnosti a práv. Jsou nadáni rozumem a svědomím a mají spolu jednat v duchu bratrství. Dnes ráno bylo hezké počasí, takže jsme šli s dětmi na procházku do parku. Co si myslíte o nové knize, kterou napsala? Je to jeden z nejzajímavějších příběhů, které jsem za poslední roky četl, a rád bych o něm věděl víc. Společnost v pondělí uvedla, že její zisky klesly kvůli vyšším nákladům, ale očekává, že se situace příští rok zlepší, až bude otevřena nová továrna. Vlak do města jezdí každou hodinu ze starého nádraží u řeky. Mnoho lidí teď pracuje z domova a do kanceláře chodí jen dva nebo tři dny v týdnu. Lékaři říkají, že děti by měly spát alespoň devět hodin a trávit méně času před obrazovkami. V restauraci jsme nenašli volný stůl, a tak jsme uvařili večeři doma a dívali se na film.`},
+	{"sk", scriptLatin, `Všetci ľudia sa rodia slobodní a sebe rovní, čo sa týka ich dôstojnosti a práv. Sú obdarení rozumom a svedomím a majú spolu jednať v bratskom duchu. Dnes ráno bolo pekné počasie, a tak sme išli s deťmi na prechádzku do parku. Čo si myslíte o novej knihe, ktorú napísala? Je to jeden z najzaujímavejších príbehov, aké som za posledné roky čítal, a rád by som o ňom vedel viac. Spoločnosť v pondelok uviedla, že jej zisky klesli pre vyššie náklady, ale očakáva, že sa situácia budúci rok zlepší, keď otvorí novú továreň. Vlak do mesta odchádza každú hodinu zo starej stanice pri rieke. Veľa ľudí teraz pracuje z domu a do kancelárie chodí len dva alebo tri dni v týždni. Lekári hovoria, že deti by mali spať aspoň deväť hodín a tráviť menej času pred obrazovkami. V reštaurácii sme nenašli voľný stôl, a tak sme navarili večeru doma a pozerali film.`},
+	{"hr", scriptLatin, `Sva ljudska bića rađaju se slobodna i jednaka u dostojanstvu i pravima. Ona su obdarena razumom i sviješću pa jedna prema drugima trebaju postupati u duhu bratstva. Jutros je bilo lijepo vrijeme, pa smo s djecom otišli u šetnju parkom. Što mislite o novoj knjizi koju je napisala? To je jedna od najzanimljivijih priča koje sam pročitao posljednjih godina i volio bih znati više o njoj. Tvrtka je u ponedjeljak objavila da je njezina dobit pala zbog viših troškova, ali očekuje da će se stanje popraviti iduće godine kada se otvori nova tvornica. Vlak za grad polazi svakih sat vremena sa starog kolodvora kraj rijeke. Mnogi ljudi sada rade od kuće i u ured idu samo dva ili tri dana u tjednu. Liječnici kažu da bi djeca trebala spavati najmanje devet sati i provoditi manje vremena pred ekranima. U restoranu nismo našli stol, pa smo večeru skuhali kod kuće i gledali film.`},
+	{"sl", scriptLatin, `Vsi ljudje se rodijo svobodni in imajo enako dostojanstvo in enake pravice. Obdarjeni so z razumom in vestjo in bi morali ravnati drug z drugim kakor bratje. Danes zjutraj je bilo lepo vreme, zato smo šli z otroki na sprehod v park. Kaj mislite o novi knjigi, ki jo je napisala? To je ena najzanimivejših zgodb, kar sem jih prebral v zadnjih letih, in rad bi izvedel več o njej. Podjetje je v ponedeljek sporočilo, da se je njegov dobiček zmanjšal zaradi višjih stroškov, vendar pričakuje, da se bo stanje prihodnje leto izboljšalo, ko bodo odprli novo tovarno. Vlak v mesto odpelje vsako uro s stare postaje ob reki. Veliko ljudi zdaj dela od doma in gre v pisarno samo dva ali tri dni na teden. Zdravniki pravijo, da bi morali otroci spati vsaj devet ur in preživeti manj časa pred zasloni. V restavraciji nismo dobili mize, zato smo večerjo skuhali doma in gledali film.`},
+	{"hu", scriptLatin, `Minden emberi lény szabadon születik és egyenlő méltósága és joga van. Az emberek, ésszel és lelkiismerettel bírván, egymással szemben testvéri szellemben kell hogy viseltessenek. Ma reggel szép idő volt, ezért a gyerekekkel sétálni mentünk a parkba. Mit gondol az új könyvről, amelyet írt? Ez az egyik legérdekesebb történet, amelyet évek óta olvastam, és szeretnék többet tudni róla. A vállalat hétfőn közölte, hogy nyeresége a magasabb költségek miatt csökkent, de arra számít, hogy a helyzet jövőre javul, amikor megnyílik az új gyár. A városba tartó vonat óránként indul a folyó melletti régi állomásról. Sokan most otthonról dolgoznak, és csak heti két-három napot mennek be az irodába. Az orvosok szerint a gyerekeknek legalább kilenc órát kellene aludniuk, és kevesebb időt kellene a képernyők előtt tölteniük. Nem kaptunk asztalt az étteremben, ezért otthon főztünk vacsorát, és megnéztünk egy filmet.`},
+	{"ro", scriptLatin, `Toate ființele umane se nasc libere și egale în demnitate și în drepturi. Ele sunt înzestrate cu rațiune și conștiință și trebuie să se comporte unele față de altele în spiritul fraternității. Vremea a fost frumoasă în această dimineață, așa că am mers la plimbare în parc cu copiii. Ce părere aveți despre noua carte pe care a scris-o? Este una dintre cele mai interesante povești pe care le-am citit în ultimii ani și aș dori să aflu mai multe despre ea. Compania a anunțat luni că profitul a scăzut din cauza costurilor mai mari, dar se așteaptă ca situația să se îmbunătățească anul viitor, când se va deschide noua fabrică. Trenul spre oraș pleacă în fiecare oră din vechea gară de lângă râu. Mulți oameni lucrează acum de acasă și merg la birou doar două sau trei zile pe săptămână. Medicii spun că copiii ar trebui să doarmă cel puțin nouă ore și să petreacă mai puțin timp în fața ecranelor. Nu am găsit o masă la restaurant, așa că am gătit cina acasă și ne-am uitat la un film. Guvernul a prezentat un plan pentru cheltuielile publice, iar parlamentul va vota legea luna viitoare.`},
+	{"tr", scriptLatin, `Bütün insanlar hür, haysiyet ve haklar bakımından eşit doğarlar. Akıl ve vicdana sahiptirler ve birbirlerine karşı kardeşlik zihniyeti ile hareket etmelidirler. Bu sabah hava çok güzeldi, bu yüzden çocuklarla parkta yürüyüşe çıktık. Onun yazdığı yeni kitap hakkında ne düşünüyorsunuz? Yıllardır okuduğum en ilginç hikâyelerden biri ve onun hakkında daha fazla bilgi edinmek istiyorum. Şirket pazartesi günü yaptığı açıklamada kârının artan maliyetler nedeniyle düştüğünü, ancak yeni fabrika açıldığında gelecek yıl durumun düzelmesini beklediğini söyledi. Şehre giden tren her saat başı nehrin yanındaki eski istasyondan kalkıyor. Birçok insan artık evden çalışıyor ve haftada sadece iki ya da üç gün ofise gidiyor. Doktorlar çocukların en az dokuz saat uyuması ve ekran karşısında daha az zaman geçirmesi gerektiğini söylüyor. Restoranda masa bulamadık, bu yüzden akşam yemeğini evde pişirdik ve bir film izledik.`},
+	{"id", scriptLatin, `Semua orang dilahirkan merdeka dan mempunyai martabat dan hak-hak yang sama. Mereka dikaruniai akal dan hati nurani dan hendaknya bergaul satu sama lain dalam semangat persaudaraan. Cuaca pagi ini sangat cerah, jadi kami berjalan-jalan di taman bersama anak-anak. Apa pendapat Anda tentang buku baru yang dia tulis? Ini adalah salah satu cerita paling menarik yang pernah saya baca dalam beberapa tahun terakhir, dan saya ingin tahu lebih banyak tentangnya. Perusahaan itu mengatakan pada hari Senin bahwa keuntungannya turun karena biaya yang lebih tinggi, tetapi berharap keadaan akan membaik tahun depan ketika pabrik baru dibuka. Kereta ke kota berangkat setiap jam dari stasiun tua di dekat sungai. Banyak orang sekarang bekerja dari rumah dan hanya pergi ke kantor dua atau tiga hari dalam seminggu. Dokter mengatakan bahwa anak-anak harus tidur setidaknya sembilan jam dan menghabiskan lebih sedikit waktu di depan layar. Kami tidak mendapat meja di restoran, jadi kami memasak makan malam di rumah dan menonton film.`},
+	{"vi", scriptLatin, `Tất cả mọi người sinh ra đều được tự do và bình đẳng về nhân phẩm và quyền lợi. Mọi con người đều được tạo hóa ban cho lý trí và lương tâm và cần phải đối xử với nhau trong tình anh em. Sáng nay thời tiết rất đẹp, vì vậy chúng tôi đã đi dạo trong công viên với các con. Bạn nghĩ gì về cuốn sách mới mà cô ấy đã viết? Đây là một trong những câu chuyện thú vị nhất mà tôi đã đọc trong nhiều năm, và tôi muốn biết thêm về nó. Công ty cho biết hôm thứ Hai rằng lợi nhuận đã giảm do chi phí tăng cao, nhưng họ hy vọng tình hình sẽ cải thiện vào năm tới khi nhà máy mới đi vào hoạt động. Tàu đi thành phố khởi hành mỗi giờ từ nhà ga cũ gần bờ sông. Nhiều người bây giờ làm việc ở nhà và chỉ đến văn phòng hai hoặc ba ngày một tuần. Các bác sĩ nói rằng trẻ em nên ngủ ít nhất chín tiếng và dành ít thời gian hơn trước màn hình. Chúng tôi không tìm được bàn ở nhà hàng, nên đã nấu bữa tối ở nhà và xem một bộ phim.`},
+	{"ru", scriptCyrillic, `Все люди рождаются свободными и равными в своем достоинстве и правах. Они наделены разумом и совестью и должны поступать в отношении друг друга в духе братства. Сегодня утром была хорошая погода, поэтому мы пошли гулять в парк с детьми. Что вы думаете о новой книге, которую она написала? Это одна из самых интересных историй, которые я прочитал за последние годы, и я хотел бы узнать о ней больше. Компания сообщила в понедельник, что ее прибыль снизилась из-за роста расходов, но ожидает, что ситуация улучшится в следующем году, когда откроется новый завод. Поезд в город отправляется каждый час со старого вокзала у реки. Многие люди сейчас работают из дома и ходят в офис только два или три дня в неделю. Врачи говорят, что детям нужно спать не меньше девяти часов и проводить меньше времени перед экранами. Мы не нашли столик в ресторане, поэтому приготовили ужин дома и посмотрели фильм.`},
+	{"uk", scriptCyrillic, `Всі люди народжуються вільними і рівними у своїй гідності та правах. Вони наділені розумом і совістю і повинні діяти у відношенні один до одного в дусі братерства. Сьогодні вранці була гарна погода, тому ми пішли гуляти в парк з дітьми. Що ви думаєте про нову книжку, яку вона написала? Це одна з найцікавіших історій, які я прочитав за останні роки, і я хотів би дізнатися про неї більше. Компанія повідомила в понеділок, що її прибуток зменшився через зростання витрат, але очікує, що ситуація покращиться наступного року, коли відкриється новий завод. Потяг до міста відправляється щогодини зі старого вокзалу біля річки. Багато людей тепер працюють з дому і ходять до офісу лише два або три дні на тиждень. Лікарі кажуть, що діти мають спати щонайменше дев'ять годин і проводити менше часу перед екранами. Ми не знайшли столика в ресторані, тому приготували вечерю вдома і подивилися фільм.`},
+	{"bg", scriptCyrillic, `Всички хора се раждат свободни и равни по достойнство и права. Те са надарени с разум и съвест и следва да се отнасят помежду си в дух на братство. Тази сутрин времето беше хубаво, затова отидохме на разходка в парка с децата. Какво мислите за новата книга, която тя написа? Това е една от най-интересните истории, които съм чел от години, и бих искал да науча повече за нея. Компанията съобщи в понеделник, че печалбата ѝ е намаляла заради по-високите разходи, но очаква положението да се подобри догодина, когато бъде открит новият завод. Влакът за града тръгва всеки час от старата гара до реката. Много хора сега работят от вкъщи и ходят в офиса само два или три дни седмично. Лекарите казват, че децата трябва да спят поне девет часа и да прекарват по-малко време пред екраните. Не намерихме маса в ресторанта, затова сготвихме вечеря у дома и гледахме филм.`},
+	{"sr", scriptCyrillic, `Сва људска бића рађају се слободна и једнака у достојанству и правима. Она су обдарена разумом и свешћу и треба једни према другима да поступају у духу братства. Јутрос је било лепо време, па смо са децом отишли у шетњу парком. Шта мислите о новој књизи коју је написала? То је једна од најзанимљивијих прича које сам прочитао последњих година и волео бих да знам више о њој. Компанија је у понедељак саопштила да је њена добит пала због већих трошкова, али очекује да ће се стање поправити следеће године када се отвори нова фабрика. Воз за град полази сваког сата са старе станице поред реке. Многи људи сада раде од куће и у канцеларију иду само два или три дана недељно. Лекари кажу да би деца требало да спавају најмање девет сати и да проводе мање времена пред екранима. У ресторану нисмо нашли сто, па смо вечеру скували код куће и гледали филм.`},
+}
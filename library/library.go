@@ -0,0 +1,289 @@
+// Package library is the server-side reading history shared by every
+// device connected to the same server. Items mirror the shape the web
+// app keeps in localStorage, so a client can use server data as-is.
+package library
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"read-aloud/jsonfile"
+)
+
+// ErrNotFound is returned for an unknown item ID.
+var ErrNotFound = errors.New("library item not found")
+
+// Item is a saved article and how far it has been read.
+type Item struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Source string `json:"source,omitempty"`
+	Text   string `json:"text"`
+	Type   string `json:"type,omitempty"` // "url", "file" or "text"
+
+	// Byline and Published (Unix milliseconds) describe the source, when
+	// extraction found them.
+	Byline    string `json:"byline,omitempty"`
+	Published int64  `json:"published,omitempty"`
+
+	// Language is the BCP-47 tag of Text, when known, so the client
+	// can pick a matching voice.
+	Language string `json:"language,omitempty"`
+
+	// Progress is the percentage read, 0–100. Position is the character
+	// offset in Text to resume from, when the client knows it.
+	Progress float64 `json:"progress"`
+	Position int     `json:"position,omitempty"`
+
+	TS      int64 `json:"ts"`      // created, Unix milliseconds
+	Updated int64 `json:"updated"` // last change, Unix milliseconds
+}
+
+// Update holds the fields of a partial item update; nil fields are left
+// unchanged.
+type Update struct {
+	Title    *string  `json:"title"`
+	Source   *string  `json:"source"`
+	Text     *string  `json:"text"`
+	Type     *string  `json:"type"`
+	Progress *float64 `json:"progress"`
+	Position *int     `json:"position"`
+}
+
+// idPattern limits client-chosen IDs to what the web app generates.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Store is a file-backed library and reading queue, kept in one
+// directory: library.json holds every item but its text, each text is
+// in texts/<id>.json, and queue.json holds the queue. Saving progress
+// thus rewrites only the small library.json. It is safe for concurrent
+// use.
+type Store struct {
+	dir string
+
+	mu    sync.Mutex
+	items map[string]*Item
+	queue []string // item IDs in play order
+}
+
+// record is an item as library.json stores it. Its Text shadows the
+// item's, so the text is left out; it is only set when reading a
+// library.json from before texts were stored apart.
+type record struct {
+	*Item
+	Text string `json:"text,omitempty"`
+}
+
+// Open loads the library from dir. Missing files are an empty library
+// and queue.
+func Open(dir string) (*Store, error) {
+	var records []record
+	if err := jsonfile.Read(filepath.Join(dir, "library.json"), &records); err != nil {
+		return nil, err
+	}
+	var queue []string
+	if err := jsonfile.Read(filepath.Join(dir, "queue.json"), &queue); err != nil {
+		return nil, err
+	}
+
+	s := &Store{dir: dir, items: make(map[string]*Item, len(records))}
+	var legacy bool
+	for _, r := range records {
+		if r.Item == nil {
+			continue
+		}
+		if r.Text != "" {
+			r.Item.Text = r.Text
+			legacy = true
+		} else if err := jsonfile.Read(s.textPath(r.ID), &r.Item.Text); err != nil {
+			return nil, err
+		}
+		s.items[r.ID] = r.Item
+	}
+	if legacy {
+		// Move the texts out of library.json.
+		for _, it := range s.items {
+			if err := s.saveTextLocked(it); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.saveLocked(); err != nil {
+			return nil, err
+		}
+	}
+	// Drop queue entries whose item is gone.
+	for _, id := range queue {
+		if _, ok := s.items[id]; ok {
+			s.queue = append(s.queue, id)
+		}
+	}
+	return s, nil
+}
+
+// List returns all items, most recently created first.
+func (s *Store) List() []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Item, 0, len(s.items))
+	for _, it := range s.items {
+		list = append(list, *it)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].TS > list[j].TS })
+	return list
+}
+
+// Get returns the item with the given ID.
+func (s *Store) Get(id string) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it, ok := s.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	return *it, nil
+}
+
+// Add saves a new item. The item's ID is kept if it is well-formed and
+// unused, so clients can create items offline and sync them later;
+// otherwise a new ID is assigned. TS defaults to now.
+func (s *Store) Add(it Item) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, taken := s.items[it.ID]; taken || !idPattern.MatchString(it.ID) {
+		id, err := newID()
+		if err != nil {
+			return Item{}, err
+		}
+		it.ID = id
+	}
+	now := time.Now().UnixMilli()
+	if it.TS == 0 {
+		it.TS = now
+	}
+	it.Updated = now
+	it.Progress = clampProgress(it.Progress)
+
+	if err := s.saveTextLocked(&it); err != nil {
+		return Item{}, err
+	}
+	s.items[it.ID] = &it
+	return it, s.saveLocked()
+}
+
+// Update applies u to the item with the given ID and returns the result.
+func (s *Store) Update(id string, u Update) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	if u.Title != nil {
+		it.Title = *u.Title
+	}
+	if u.Source != nil {
+		it.Source = *u.Source
+	}
+	if u.Text != nil && *u.Text != it.Text {
+		it.Text = *u.Text
+		if err := s.saveTextLocked(it); err != nil {
+			return Item{}, err
+		}
+	}
+	if u.Type != nil {
+		it.Type = *u.Type
+	}
+	if u.Progress != nil {
+		it.Progress = clampProgress(*u.Progress)
+	}
+	if u.Position != nil && *u.Position >= 0 {
+		it.Position = *u.Position
+	}
+	it.Updated = time.Now().UnixMilli()
+	return *it, s.saveLocked()
+}
+
+// Delete removes the item with the given ID, and takes it out of the
+// queue.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.items, id)
+	if i := s.queueIndexLocked(id); i >= 0 {
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+		if err := s.saveQueueLocked(); err != nil {
+			return err
+		}
+	}
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	if err := os.Remove(s.textPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete library text: %w", err)
+	}
+	return nil
+}
+
+// saveLocked writes the library file, without the items' texts. s.mu
+// must be held.
+func (s *Store) saveLocked() error {
+	list := make([]record, 0, len(s.items))
+	for _, it := range s.items {
+		list = append(list, record{Item: it})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].TS < list[j].TS })
+	if err := jsonfile.Write(filepath.Join(s.dir, "library.json"), list); err != nil {
+		return fmt.Errorf("save library: %w", err)
+	}
+	return nil
+}
+
+// saveTextLocked writes the text file of it. s.mu must be held.
+func (s *Store) saveTextLocked(it *Item) error {
+	if err := os.MkdirAll(filepath.Join(s.dir, "texts"), 0o755); err != nil {
+		return fmt.Errorf("save library text: %w", err)
+	}
+	if err := jsonfile.Write(s.textPath(it.ID), it.Text); err != nil {
+		return fmt.Errorf("save library text: %w", err)
+	}
+	return nil
+}
+
+// textPath returns the path of the text file of the item with the
+// given ID.
+func (s *Store) textPath(id string) string {
+	return filepath.Join(s.dir, "texts", id+".json")
+}
+
+func clampProgress(p float64) float64 {
+	if p < 0 {
+		return 0
+	}
+	if p > 100 {
+		return 100
+	}
+	return p
+}
+
+// newID returns a random 16-character hex ID.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
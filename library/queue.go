@@ -0,0 +1,140 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"read-aloud/jsonfile"
+)
+
+// ErrBadOrder is returned by Reorder when the new order is not a
+// permutation of the current queue.
+var ErrBadOrder = errors.New("new order must list every queued item exactly once")
+
+// Queue returns the queued items in play order.
+func (s *Store) Queue() []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Item, 0, len(s.queue))
+	for _, id := range s.queue {
+		list = append(list, *s.items[id])
+	}
+	return list
+}
+
+// Enqueue adds an item to the queue at index pos, or at the end if pos
+// is negative or past the end. An item that is already queued is moved.
+func (s *Store) Enqueue(id string, pos int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[id]; !ok {
+		return ErrNotFound
+	}
+	if i := s.queueIndexLocked(id); i >= 0 {
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	}
+	if pos < 0 || pos > len(s.queue) {
+		pos = len(s.queue)
+	}
+	s.queue = append(s.queue, "")
+	copy(s.queue[pos+1:], s.queue[pos:])
+	s.queue[pos] = id
+	return s.saveQueueLocked()
+}
+
+// Dequeue removes an item from the queue. The item stays in the library.
+func (s *Store) Dequeue(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.queueIndexLocked(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	return s.saveQueueLocked()
+}
+
+// Reorder replaces the queue order with ids, which must contain exactly
+// the currently queued IDs.
+func (s *Store) Reorder(ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(ids) != len(s.queue) {
+		return ErrBadOrder
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] || s.queueIndexLocked(id) < 0 {
+			return ErrBadOrder
+		}
+		seen[id] = true
+	}
+	s.queue = append([]string(nil), ids...)
+	return s.saveQueueLocked()
+}
+
+// Next returns the queued item that follows after. If after is empty or
+// not queued, it returns the head of the queue. ok is false when there
+// is nothing to play next.
+func (s *Store) Next(after string) (it Item, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.queueIndexLocked(after) + 1 // 0 when after is not queued
+	if i >= len(s.queue) {
+		return Item{}, false
+	}
+	return *s.items[s.queue[i]], true
+}
+
+// Advance is called when finished has been read to the end. It removes
+// finished from the queue, marks it fully read and returns the item to
+// play next, as Next would have before the removal.
+func (s *Store) Advance(finished string) (next Item, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.queueIndexLocked(finished)
+	if i >= 0 {
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+		if err := s.saveQueueLocked(); err != nil {
+			return Item{}, false, err
+		}
+	} else {
+		i = 0
+	}
+	if it, found := s.items[finished]; found && it.Progress < 100 {
+		it.Progress = 100
+		if err := s.saveLocked(); err != nil {
+			return Item{}, false, err
+		}
+	}
+
+	if i >= len(s.queue) {
+		return Item{}, false, nil
+	}
+	return *s.items[s.queue[i]], true, nil
+}
+
+// queueIndexLocked returns the queue position of id, or -1. s.mu must
+// be held.
+func (s *Store) queueIndexLocked(id string) int {
+	for i, qid := range s.queue {
+		if qid == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// saveQueueLocked writes the queue file. s.mu must be held.
+func (s *Store) saveQueueLocked() error {
+	queue := s.queue
+	if queue == nil {
+		queue = []string{}
+	}
+	if err := jsonfile.Write(filepath.Join(s.dir, "queue.json"), queue); err != nil {
+		return fmt.Errorf("save queue: %w", err)
+	}
+	return nil
+}
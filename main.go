@@ -13,7 +13,12 @@ import (
 	"runtime"
 	"time"
 
+	"read-aloud/extractor"
 	"read-aloud/handlers"
+	"read-aloud/jobs"
+	"read-aloud/library"
+	"read-aloud/podcast"
+	"read-aloud/tts"
 )
 
 //go:embed web/*
@@ -30,9 +35,70 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Server-side speech is optional; the web app speaks in the browser.
+	synth, err := tts.FromEnv()
+	if err != nil {
+		log.Printf("server-side speech disabled: %v", err)
+	}
+
+	// URL fetching may only reach what the egress policy allows.
+	policy, err := extractor.PolicyFromEnv()
+	if err != nil {
+		log.Fatalf("egress policy: %v", err)
+	}
+	extractor.SetPolicy(policy)
+
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.FS(webContent)))
-	mux.HandleFunc("/api/extract", handlers.Extract)
+	mux.HandleFunc("/api/speak", handlers.Speak(synth))
+	mux.HandleFunc("/api/export", handlers.Export(synth))
+
+	// Saved data lives in the user config dir; without it the server
+	// still works, just without the shared library, queue and podcast
+	// feed.
+	var lib *library.Store
+	dir, err := dataDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		log.Printf("saved data disabled: %v", err)
+	} else {
+		if lib, err = library.Open(dir); err != nil {
+			log.Printf("shared library disabled: %v", err)
+		} else {
+			mux.HandleFunc("GET /api/library", handlers.ListItems(lib))
+			mux.HandleFunc("POST /api/library", handlers.CreateItem(lib))
+			mux.HandleFunc("GET /api/library/{id}", handlers.GetItem(lib))
+			mux.HandleFunc("PUT /api/library/{id}", handlers.UpdateItem(lib))
+			mux.HandleFunc("DELETE /api/library/{id}", handlers.DeleteItem(lib))
+			mux.HandleFunc("PUT /api/library/{id}/progress", handlers.UpdateProgress(lib))
+
+			mux.HandleFunc("GET /api/queue", handlers.ListQueue(lib))
+			mux.HandleFunc("POST /api/queue", handlers.EnqueueItem(lib))
+			mux.HandleFunc("PUT /api/queue", handlers.ReorderQueue(lib))
+			mux.HandleFunc("DELETE /api/queue/{id}", handlers.DequeueItem(lib))
+			mux.HandleFunc("GET /api/queue/next", handlers.NextInQueue(lib))
+			mux.HandleFunc("POST /api/queue/advance", handlers.AdvanceQueue(lib))
+		}
+
+		if episodes, err := podcast.Open(filepath.Join(dir, "episodes")); err != nil {
+			log.Printf("podcast feed disabled: %v", err)
+		} else {
+			mux.HandleFunc("GET /api/episodes", handlers.ListEpisodes(episodes))
+			mux.HandleFunc("POST /api/episodes", handlers.CreateEpisode(episodes, synth))
+			mux.HandleFunc("DELETE /api/episodes/{id}", handlers.DeleteEpisode(episodes))
+			mux.HandleFunc("GET /episodes/{file}", handlers.EpisodeAudio(episodes))
+			mux.HandleFunc("GET /feed.xml", handlers.Feed(episodes))
+		}
+	}
+
+	mux.HandleFunc("/api/extract", handlers.Extract(lib))
+	jobManager := jobs.NewManager()
+	mux.HandleFunc("POST /api/jobs", handlers.CreateJob(jobManager, lib))
+	mux.HandleFunc("GET /api/jobs/{id}", handlers.GetJob(jobManager))
+	mux.HandleFunc("GET /api/jobs/{id}/events", handlers.JobEvents(jobManager))
+	mux.HandleFunc("DELETE /api/jobs/{id}", handlers.CancelJob(jobManager))
 	// Keep legacy endpoints for backwards compatibility.
 	mux.HandleFunc("/api/extract-url", handlers.ExtractURL)
 	mux.HandleFunc("/api/extract-pdf", handlers.ExtractPDF)
@@ -59,6 +125,19 @@ func main() {
 	select {} // Keep running
 }
 
+// dataDir returns the directory for saved data: $READ_ALOUD_DATA_DIR,
+// or read-aloud inside the user config dir.
+func dataDir() (string, error) {
+	if dir := os.Getenv("READ_ALOUD_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "read-aloud"), nil
+}
+
 // getLANIP returns the first non-loopback IPv4 address, or "".
 func getLANIP() string {
 	addrs, err := net.InterfaceAddrs()
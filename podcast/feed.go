@@ -0,0 +1,127 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const itunesNS = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+
+// Channel describes the feed as a whole.
+type Channel struct {
+	Title       string
+	Description string
+	Link        string // site URL, e.g. http://192.168.1.42:8080/
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Itunes  string     `xml:"xmlns:itunes,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	Description string      `xml:"description"`
+	Language    string      `xml:"language,omitempty"`
+	Author      string      `xml:"itunes:author"`
+	Summary     string      `xml:"itunes:summary"`
+	Explicit    string      `xml:"itunes:explicit"`
+	Category    rssCategory `xml:"itunes:category"`
+	Items       []rssItem   `xml:"item"`
+}
+
+type rssCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description"`
+	GUID        rssGUID      `xml:"guid"`
+	PubDate     string       `xml:"pubDate"`
+	Link        string       `xml:"link,omitempty"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+	Duration    string       `xml:"itunes:duration"`
+	Summary     string       `xml:"itunes:summary,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// WriteFeed writes an RSS 2.0 feed with iTunes tags for the ready
+// episodes in list. audioURL maps an episode ID to its enclosure URL.
+// Episodes still rendering or failed are left out.
+func WriteFeed(w io.Writer, ch Channel, list []Episode, audioURL func(id string) string) error {
+	feed := rss{
+		Version: "2.0",
+		Itunes:  itunesNS,
+		Channel: rssChannel{
+			Title:       ch.Title,
+			Link:        ch.Link,
+			Description: ch.Description,
+			Author:      ch.Title,
+			Summary:     ch.Description,
+			Explicit:    "false",
+			Category:    rssCategory{Text: "News"},
+		},
+	}
+
+	for _, ep := range list {
+		if ep.Status != StatusReady {
+			continue
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       ep.Title,
+			Description: episodeDescription(ep),
+			GUID:        rssGUID{IsPermaLink: "false", Value: ep.ID},
+			PubDate:     ep.Created.Format(time.RFC1123Z),
+			Link:        ep.SourceURL,
+			Enclosure: rssEnclosure{
+				URL:    audioURL(ep.ID),
+				Length: ep.Size,
+				Type:   "audio/wav",
+			},
+			Duration: formatDuration(ep.Seconds),
+			Summary:  ep.Excerpt,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
+
+// episodeDescription is the excerpt followed by the source URL.
+func episodeDescription(ep Episode) string {
+	var parts []string
+	if ep.Excerpt != "" {
+		parts = append(parts, ep.Excerpt)
+	}
+	if ep.SourceURL != "" {
+		parts = append(parts, "Source: "+ep.SourceURL)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// formatDuration renders seconds as HH:MM:SS for itunes:duration.
+func formatDuration(seconds float64) string {
+	s := int(seconds + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+}
@@ -0,0 +1,218 @@
+// Package podcast keeps rendered articles as podcast episodes and
+// publishes them as an RSS feed that podcast apps can subscribe to.
+package podcast
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"read-aloud/jsonfile"
+	"read-aloud/tts"
+)
+
+// Episode statuses. Only ready episodes have audio and appear in the feed.
+const (
+	StatusRendering = "rendering"
+	StatusReady     = "ready"
+	StatusFailed    = "failed"
+)
+
+// Episode is a saved article and the state of its rendered audio.
+type Episode struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	SourceURL string    `json:"sourceUrl,omitempty"`
+	Excerpt   string    `json:"excerpt,omitempty"`
+	Created   time.Time `json:"created"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Size      int64     `json:"size,omitempty"`
+	Seconds   float64   `json:"seconds,omitempty"`
+}
+
+// Store keeps episode metadata in episodes.json and audio files next to
+// it in the same directory. It is safe for concurrent use.
+type Store struct {
+	dir string
+
+	mu       sync.Mutex
+	episodes map[string]*Episode
+}
+
+// Open loads the store in dir, creating the directory if needed.
+// Episodes left rendering by a previous run are marked failed, since
+// their render goroutine is gone.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var list []*Episode
+	if err := jsonfile.Read(filepath.Join(dir, "episodes.json"), &list); err != nil {
+		return nil, err
+	}
+
+	s := &Store{dir: dir, episodes: make(map[string]*Episode, len(list))}
+	for _, ep := range list {
+		if ep.Status == StatusRendering {
+			ep.Status = StatusFailed
+			ep.Error = "interrupted by server restart"
+		}
+		s.episodes[ep.ID] = ep
+	}
+	return s, nil
+}
+
+// Create adds a new episode in the rendering state and returns it.
+func (s *Store) Create(title, sourceURL, excerpt string) (Episode, error) {
+	id, err := newID()
+	if err != nil {
+		return Episode{}, err
+	}
+	ep := &Episode{
+		ID:        id,
+		Title:     title,
+		SourceURL: sourceURL,
+		Excerpt:   excerpt,
+		Created:   time.Now().UTC(),
+		Status:    StatusRendering,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.episodes[id] = ep
+	return *ep, s.saveLocked()
+}
+
+// SaveAudio writes an episode's rendered audio as WAV and marks it ready.
+// The file is written under a temp name and renamed, so the feed never
+// points at a partial file.
+func (s *Store) SaveAudio(id string, r *tts.Rendering) error {
+	wav, size, err := r.WAV()
+	if err != nil {
+		return err
+	}
+	tmp := s.AudioPath(id) + ".tmp"
+	if err := writeFile(tmp, wav); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, s.AudioPath(id)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ep, ok := s.episodes[id]
+	if !ok {
+		// Deleted while rendering.
+		os.Remove(s.AudioPath(id))
+		return nil
+	}
+	ep.Status = StatusReady
+	ep.Error = ""
+	ep.Size = size
+	ep.Seconds = r.Duration().Seconds()
+	return s.saveLocked()
+}
+
+// writeFile copies r to a new file at path.
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Fail marks an episode's render as failed.
+func (s *Store) Fail(id string, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ep, ok := s.episodes[id]
+	if !ok {
+		return nil
+	}
+	ep.Status = StatusFailed
+	ep.Error = cause.Error()
+	return s.saveLocked()
+}
+
+// Get returns the episode with the given ID.
+func (s *Store) Get(id string) (Episode, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ep, ok := s.episodes[id]
+	if !ok {
+		return Episode{}, false
+	}
+	return *ep, true
+}
+
+// List returns all episodes, newest first.
+func (s *Store) List() []Episode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Episode, 0, len(s.episodes))
+	for _, ep := range s.episodes {
+		list = append(list, *ep)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.After(list[j].Created)
+	})
+	return list
+}
+
+// Delete removes an episode and its audio. It reports whether the
+// episode existed.
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.episodes[id]; !ok {
+		return false, nil
+	}
+	delete(s.episodes, id)
+	os.Remove(s.AudioPath(id))
+	return true, s.saveLocked()
+}
+
+// AudioPath returns the path of an episode's WAV file.
+func (s *Store) AudioPath(id string) string {
+	return filepath.Join(s.dir, id+".wav")
+}
+
+// saveLocked writes the episode index. s.mu must be held.
+func (s *Store) saveLocked() error {
+	list := make([]*Episode, 0, len(s.episodes))
+	for _, ep := range s.episodes {
+		list = append(list, ep)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	if err := jsonfile.Write(filepath.Join(s.dir, "episodes.json"), list); err != nil {
+		return fmt.Errorf("save episodes: %w", err)
+	}
+	return nil
+}
+
+// newID returns a random 16-character hex ID.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,272 @@
+// Package segment splits text into the sentences a player speaks one at
+// a time. Each segment carries its offsets in the original text, so a
+// client can seek to it, resume at it and highlight it.
+//
+// Offsets count UTF-16 code units, the unit of JavaScript string
+// indices, so a browser can pass them straight to String.slice.
+package segment
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest segment, in UTF-16 units. Longer sentences
+// are split at a clause break or space: some speech engines cut off or
+// stall on very long utterances.
+const MaxLength = 300
+
+// Segment is one sentence, or one piece of an overlong sentence.
+type Segment struct {
+	Text      string `json:"text"`
+	Start     int    `json:"start"` // UTF-16 offset of the first character
+	End       int    `json:"end"`   // UTF-16 offset just past the last
+	Paragraph int    `json:"paragraph"`
+}
+
+// Split splits text into segments. Paragraphs are the non-blank lines
+// of text, numbered from 0; a segment never spans two paragraphs.
+func Split(text string) []Segment {
+	segments := []Segment{}
+	pos := newOffsets(text)
+	paragraph := 0
+	lineStart := 0
+	for lineStart <= len(text) {
+		lineEnd := strings.IndexByte(text[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += lineStart
+		}
+		line := text[lineStart:lineEnd]
+		if strings.TrimSpace(line) != "" {
+			for _, s := range sentences(line) {
+				for _, piece := range splitLong(line, s) {
+					start, end := trimSpan(line, piece[0], piece[1])
+					if start == end {
+						continue
+					}
+					segments = append(segments, Segment{
+						Text:      line[start:end],
+						Start:     pos.utf16(lineStart + start),
+						End:       pos.utf16(lineStart + end),
+						Paragraph: paragraph,
+					})
+				}
+			}
+			paragraph++
+		}
+		lineStart = lineEnd + 1
+	}
+	return segments
+}
+
+// sentences returns the byte spans of the sentences in line.
+func sentences(line string) [][2]int {
+	var spans [][2]int
+	start := 0
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		if !isTerminator(r) {
+			continue
+		}
+		// Take in repeated terminators ("?!", "...") and closing quotes
+		// and brackets.
+		end := i
+		for end < len(line) {
+			r, size := utf8.DecodeRuneInString(line[end:])
+			if !isTerminator(r) && !isCloser(r) {
+				break
+			}
+			end += size
+		}
+		if isBoundary(line, start, i-size, end, r) {
+			spans = append(spans, [2]int{start, end})
+			start = end
+		}
+		i = end
+	}
+	if start < len(line) {
+		spans = append(spans, [2]int{start, len(line)})
+	}
+	return spans
+}
+
+// isBoundary reports whether the terminator term at line[at:] ends a
+// sentence, given that the sentence began at start and the terminator
+// with its closers runs to end.
+func isBoundary(line string, start, at, end int, term rune) bool {
+	if isFullWidthTerminator(term) {
+		return true // CJK text has no space after a sentence
+	}
+	next, _ := utf8.DecodeRuneInString(line[end:])
+	if end < len(line) && !unicode.IsSpace(next) {
+		return false // 3.14, example.com, "?!" inside a word
+	}
+	after := strings.TrimLeftFunc(line[end:], unicode.IsSpace)
+	if after == "" {
+		return true
+	}
+	first, _ := utf8.DecodeRuneInString(after)
+	if unicode.IsLower(first) {
+		return false // "approx. three", "... and then"
+	}
+	if term != '.' || end-at > 1 {
+		return true
+	}
+	word := lastWord(line[start:at])
+	switch {
+	case len(word) == 1 && unicode.IsUpper(rune(word[0])):
+		return false // an initial: J. R. R. Tolkien
+	case titles[word]:
+		return false // Dr. Smith
+	case references[strings.ToLower(word)] && unicode.IsDigit(first):
+		return false // Fig. 3, No. 7
+	case inlineAbbreviations[strings.ToLower(word)]:
+		return false // e.g. Paris
+	}
+	return true
+}
+
+// lastWord returns the word just before a period, without leading
+// punctuation.
+func lastWord(s string) string {
+	i := strings.LastIndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '(' || r == '"' || r == '\'' || r == '“'
+	})
+	return s[i+1:]
+}
+
+// titles precede a name and never end a sentence.
+var titles = map[string]bool{
+	"Mr": true, "Mrs": true, "Ms": true, "Dr": true, "Prof": true,
+	"St": true, "Sr": true, "Jr": true, "Gen": true, "Sen": true,
+	"Rep": true, "Gov": true, "Capt": true, "Lt": true, "Col": true,
+	"Rev": true, "Hon": true, "Mt": true, "Ft": true,
+}
+
+// references precede a number.
+var references = map[string]bool{
+	"fig": true, "figs": true, "no": true, "nos": true, "p": true,
+	"pp": true, "vol": true, "ch": true, "sec": true, "eq": true,
+	"eqs": true, "art": true, "ref": true, "tab": true, "op": true,
+}
+
+// inlineAbbreviations are followed by more of the same sentence.
+var inlineAbbreviations = map[string]bool{
+	"e.g": true, "i.e": true, "vs": true, "cf": true, "viz": true,
+}
+
+// isTerminator reports whether r can end a sentence: Latin, CJK,
+// Devanagari, Arabic, Armenian, Ethiopic and Greek sentence enders.
+func isTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…', '‼', '⁇', '⁈', '⁉',
+		'।', '॥', '؟', '۔', '։', '።', '፧', '\u037e': // ; is the Greek question mark
+		return true
+	}
+	return isFullWidthTerminator(r)
+}
+
+func isFullWidthTerminator(r rune) bool {
+	switch r {
+	case '。', '！', '？', '｡', '．':
+		return true
+	}
+	return false
+}
+
+// isCloser reports whether r closes a quotation or parenthesis and
+// belongs to the sentence before it.
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', '”', '’', '»', '›', ')', ']', '}', '」', '』', '）', '】', '〉', '》':
+		return true
+	}
+	return false
+}
+
+// splitLong splits the sentence span s of line into pieces of at most
+// MaxLength UTF-16 units, at the last clause break (comma, semicolon,
+// colon, dash) or failing that the last space that fits.
+func splitLong(line string, s [2]int) [][2]int {
+	var pieces [][2]int
+	start, end := s[0], s[1]
+	for utf16Len(line[start:end]) > MaxLength {
+		cut, clause, space := 0, 0, 0
+		units := 0
+		for i, r := range line[start:end] {
+			units += utf16.RuneLen(r)
+			if units > MaxLength {
+				break
+			}
+			if unicode.IsSpace(r) && i > 0 {
+				prev, _ := utf8.DecodeLastRuneInString(line[start : start+i])
+				if strings.ContainsRune(",;:—–、，；：", prev) {
+					clause = start + i
+				}
+				space = start + i
+			}
+			cut = start + i + utf8.RuneLen(r)
+		}
+		switch {
+		case clause > start:
+			cut = clause
+		case space > start:
+			cut = space
+		}
+		pieces = append(pieces, [2]int{start, cut})
+		start = cut
+	}
+	return append(pieces, [2]int{start, end})
+}
+
+// trimSpan narrows the span [start, end) of s to exclude surrounding
+// whitespace.
+func trimSpan(s string, start, end int) (int, int) {
+	for start < end {
+		r, size := utf8.DecodeRuneInString(s[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	for end > start {
+		r, size := utf8.DecodeLastRuneInString(s[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	return start, end
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// offsets converts byte offsets in a text to UTF-16 offsets. Lookups
+// must be in increasing order.
+type offsets struct {
+	text  string
+	bytes int // byte offset reached so far
+	units int // the same offset in UTF-16 units
+}
+
+func newOffsets(text string) *offsets { return &offsets{text: text} }
+
+func (o *offsets) utf16(b int) int {
+	for o.bytes < b {
+		r, size := utf8.DecodeRuneInString(o.text[o.bytes:])
+		o.bytes += size
+		o.units += utf16.RuneLen(r)
+	}
+	return o.units
+}
@@ -0,0 +1,125 @@
+package speechnorm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rewrite is a regexp replacement; repl may refer to groups as $1.
+type rewrite struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+func rw(pattern, repl string) rewrite {
+	return rewrite{regexp.MustCompile(pattern), repl}
+}
+
+func applyRewrites(s string, rules []rewrite) string {
+	for _, r := range rules {
+		s = r.re.ReplaceAllString(s, r.repl)
+	}
+	return s
+}
+
+// symbols are read as words. Ones that only make sense next to a number
+// are matched only there.
+var symbols = []rewrite{
+	rw(`(\d)\s?%`, "$1 percent"),
+	rw(`(\d)\s?°\s?C\b`, "$1 degrees Celsius"),
+	rw(`(\d)\s?°\s?F\b`, "$1 degrees Fahrenheit"),
+	rw(`(\d)\s?°`, "$1 degrees"),
+	rw(`(\d)\s?[–—]\s?(\d)`, "$1 to $2"),
+	rw(`(\d)\s?×\s?(\d)`, "$1 by $2"),
+	rw(`(\d) x (\d)`, "$1 by $2"),
+	rw(`(^|\s)~\s?(\d)`, "${1}about $2"),
+	rw(`(^|\s)#(\d)`, "${1}number $2"),
+	rw(`§\s?(\d)`, "section $1"),
+	rw(`\s&\s`, " and "),
+	rw(`\s\+\s`, " plus "),
+	rw(`\s=\s`, " equals "),
+	rw(`\s?±\s?`, " plus or minus "),
+	rw(`\s?≈\s?`, " approximately "),
+	rw(`\s?≠\s?`, " is not equal to "),
+	rw(`\s?≤\s?`, " is at most "),
+	rw(`\s?≥\s?`, " is at least "),
+	rw(`\s?[→⇒]\s?`, " to "),
+	rw(`…`, "..."),
+	rw(`[•·▪►]\s*`, ""),
+}
+
+func expandSymbols(s string) string {
+	return applyRewrites(s, symbols)
+}
+
+// abbreviations are expanded where a synthesizer would read them
+// letter by letter or stop at their period. Titles and references
+// only expand before a name or number, so "St." and "No." elsewhere
+// are left alone.
+var abbreviations = []rewrite{
+	rw(`\b[Ee]\.\s?g\.,?`, "for example,"),
+	rw(`\b[Ii]\.\s?e\.,?`, "that is,"),
+	rw(`\b[Cc]f\.`, "compare"),
+	rw(`\b[Vv]iz\.`, "namely"),
+	rw(`\b[Vv]s\.?(\s)`, "versus$1"),
+	rw(`\b[Aa]pprox\.`, "approximately"),
+	rw(`\b[Cc]a\.\s?(\d)`, "circa $1"),
+	rw(`\bFigs\.\s?(\d)`, "Figures $1"),
+	rw(`\bFig\.\s?(\d)`, "Figure $1"),
+	rw(`\bEqs?\.\s?(\d)`, "Equation $1"),
+	rw(`\bTab\.\s?(\d)`, "Table $1"),
+	rw(`\bSec\.\s?(\d)`, "Section $1"),
+	rw(`\bCh\.\s?(\d)`, "Chapter $1"),
+	rw(`\bVol\.\s?(\d)`, "Volume $1"),
+	rw(`\b[Nn]o\.\s?(\d)`, "number $1"),
+	rw(`\b[Nn]os\.\s?(\d)`, "numbers $1"),
+	rw(`\bpp\.\s?(\d)`, "pages $1"),
+	rw(`\bp\.\s?(\d)`, "page $1"),
+	rw(`\bDr\.(\s+[A-Z])`, "Doctor$1"),
+	rw(`\bMr\.(\s+[A-Z])`, "Mister$1"),
+	rw(`\bMrs\.(\s+[A-Z])`, "Missus$1"),
+	rw(`\bMs\.(\s+[A-Z])`, "Miz$1"),
+	rw(`\bProf\.(\s+[A-Z])`, "Professor$1"),
+	rw(`\bSt\.(\s+[A-Z])`, "Saint$1"),
+	rw(`\bGen\.(\s+[A-Z])`, "General$1"),
+	rw(`\bSen\.(\s+[A-Z])`, "Senator$1"),
+	rw(`\bRep\.(\s+[A-Z])`, "Representative$1"),
+}
+
+// sentenceAbbreviations can end a sentence, in which case their period
+// is kept as the sentence's.
+var sentenceAbbreviations = []struct {
+	re   *regexp.Regexp
+	word string
+}{
+	{regexp.MustCompile(`\betc\.`), "et cetera"},
+	{regexp.MustCompile(`\bet al\.`), "and others"},
+	{regexp.MustCompile(`\bJr\.`), "Junior"},
+	{regexp.MustCompile(`\bSr\.`), "Senior"},
+}
+
+func expandAbbreviations(s string) string {
+	s = applyRewrites(s, abbreviations)
+	for _, a := range sentenceAbbreviations {
+		s = replaceKeepingEnd(s, a.re, a.word)
+	}
+	return s
+}
+
+// replaceKeepingEnd replaces matches of re with word, keeping the
+// period when it ends the sentence: at the end of s or before a capital.
+func replaceKeepingEnd(s string, re *regexp.Regexp, word string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringIndex(s, -1) {
+		b.WriteString(s[last:m[0]])
+		b.WriteString(word)
+		rest := strings.TrimLeft(s[m[1]:], " \t")
+		if rest == "" || rest[0] >= 'A' && rest[0] <= 'Z' || rest[0] == '\n' {
+			b.WriteString(".")
+		}
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
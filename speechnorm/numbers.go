@@ -0,0 +1,374 @@
+package speechnorm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	smallNumbers = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+		"seventeen", "eighteen", "nineteen",
+	}
+	tensWords = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	scales    = []struct {
+		value uint64
+		name  string
+	}{
+		{1e12, "trillion"}, {1e9, "billion"}, {1e6, "million"}, {1e3, "thousand"},
+	}
+)
+
+// maxSpelled is the largest number spelled as words; longer numbers
+// (IDs, phone numbers) are read digit by digit.
+const maxSpelled = 1e15 - 1
+
+// cardinal spells out n, e.g. 1205 → "one thousand two hundred five".
+func cardinal(n uint64) string {
+	if n < 1000 {
+		return belowThousand(int(n))
+	}
+	var parts []string
+	for _, s := range scales {
+		if n >= s.value {
+			parts = append(parts, belowThousand(int(n/s.value))+" "+s.name)
+			n %= s.value
+		}
+	}
+	if n > 0 {
+		parts = append(parts, belowThousand(int(n)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func belowThousand(n int) string {
+	var parts []string
+	if n >= 100 {
+		parts = append(parts, smallNumbers[n/100]+" hundred")
+		n %= 100
+		if n == 0 {
+			return parts[0]
+		}
+	}
+	switch {
+	case n < 20:
+		parts = append(parts, smallNumbers[n])
+	case n%10 == 0:
+		parts = append(parts, tensWords[n/10])
+	default:
+		parts = append(parts, tensWords[n/10]+"-"+smallNumbers[n%10])
+	}
+	return strings.Join(parts, " ")
+}
+
+// irregularOrdinals are the ordinals not formed by adding "th".
+var irregularOrdinals = map[string]string{
+	"one": "first", "two": "second", "three": "third", "five": "fifth",
+	"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+}
+
+// ordinal spells out n as an ordinal, e.g. 23 → "twenty-third".
+func ordinal(n uint64) string {
+	words := cardinal(n)
+	cut := strings.LastIndexAny(words, " -") + 1
+	head, last := words[:cut], words[cut:]
+	switch {
+	case irregularOrdinals[last] != "":
+		last = irregularOrdinals[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return head + last
+}
+
+// year spells a year the way it is said: 1905 → "nineteen oh five",
+// 2000 → "two thousand", 2024 → "twenty twenty-four".
+func year(n int) string {
+	switch {
+	case n < 1100 || n > 2099 || n >= 2000 && n < 2010:
+		return cardinal(uint64(n))
+	case n%100 == 0:
+		return belowThousand(n/100) + " hundred"
+	case n%100 < 10:
+		return belowThousand(n/100) + " oh " + smallNumbers[n%100]
+	}
+	return belowThousand(n/100) + " " + belowThousand(n%100)
+}
+
+// digitWords reads a string of digits one by one.
+func digitWords(s string) string {
+	words := make([]string, 0, len(s))
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			words = append(words, smallNumbers[c-'0'])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// spellNumber spells a number as written, with optional thousands
+// separators and decimals: "1,234.5" → "one thousand two hundred
+// thirty-four point five".
+func spellNumber(s string) string {
+	whole, frac, hasFrac := strings.Cut(strings.ReplaceAll(s, ",", ""), ".")
+	if len(whole) > 1 && whole[0] == '0' && !hasFrac {
+		return digitWords(whole) // 007, zip codes
+	}
+	n, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil || n > maxSpelled {
+		return digitWords(s)
+	}
+	words := cardinal(n)
+	if hasFrac {
+		words += " point " + digitWords(frac)
+	}
+	return words
+}
+
+var (
+	// numberPattern matches a number with optional thousands separators
+	// and decimals, an ordinal suffix, or a decade's "s".
+	numberPattern = regexp.MustCompile(`\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?(?:st|nd|rd|th|s)?`)
+
+	// yearContext are words after which a four-digit number is a year.
+	yearContext = regexp.MustCompile(`(?i)\b(?:in|since|by|until|till|from|to|of|before|after|during|around|circa|year|early|late|mid|spring|summer|autumn|fall|winter)[ \t]+$`)
+)
+
+// expandNumbers spells out the numbers in s. Numbers glued to letters
+// (mp3, B2B) and dotted version numbers (1.2.3) are left alone, as are
+// numbers too long to say.
+func expandNumbers(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range numberPattern.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		if start > 0 && isWordByte(s[start-1]) || end < len(s) && isWordByte(s[end]) {
+			continue
+		}
+		if start > 0 && s[start-1] == '.' || end+1 < len(s) && s[end] == '.' && isDigit(s[end+1]) {
+			continue // part of a version number or IP address
+		}
+		b.WriteString(s[last:start])
+		if start > 0 && s[start-1] == '-' && (start == 1 || s[start-2] == ' ' || s[start-2] == '(') {
+			// A minus sign rather than a hyphen.
+			str := b.String()
+			b.Reset()
+			b.WriteString(str[:len(str)-1] + "minus ")
+		}
+		b.WriteString(spellToken(s[start:end], s[:start]))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// spellToken spells one match of numberPattern; before is the text
+// preceding it, which decides whether a four-digit number is a year.
+func spellToken(tok, before string) string {
+	num := strings.TrimRight(tok, "stndrh")
+	suffix := tok[len(num):]
+	n, err := strconv.Atoi(num)
+	isYear := err == nil && len(num) == 4 && n >= 1100 && n <= 2099
+	switch {
+	case suffix == "s" && isYear && n%10 == 0: // 1990s
+		return strings.TrimSuffix(year(n), "y") + pluralSuffix(year(n))
+	case suffix == "s" && err == nil && n%10 == 0 && n >= 10 && n < 100: // the 80s
+		return strings.TrimSuffix(cardinal(uint64(n)), "y") + "ies"
+	case suffix != "" && suffix != "s" && err == nil:
+		return ordinal(uint64(n))
+	case suffix == "s":
+		return spellNumber(num) + "s"
+	case isYear && yearContext.MatchString(before):
+		return year(n)
+	}
+	return spellNumber(num)
+}
+
+func pluralSuffix(words string) string {
+	if strings.HasSuffix(words, "y") {
+		return "ies"
+	}
+	return "s"
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isWordByte(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+// Currency amounts such as "$1.2B", "€3,000", "£5.99" and "$20 million".
+var currencyPattern = regexp.MustCompile(
+	`([$€£¥₹])\s?(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d+))?(?:\s(thousand|million|billion|trillion)\b|(mn|bn|tn|[KkmMBT])\b)?`)
+
+var currencyNames = map[string][2]string{
+	"$": {"dollar", "cent"},
+	"€": {"euro", "cent"},
+	"£": {"pound", "penny"},
+	"¥": {"yen", ""},
+	"₹": {"rupee", "paisa"},
+}
+
+var currencyScales = map[string]string{
+	"K": "thousand", "k": "thousand",
+	"m": "million", "M": "million", "mn": "million",
+	"B": "billion", "bn": "billion",
+	"T": "trillion", "tn": "trillion",
+}
+
+// expandCurrency spells out amounts of money with the currency after
+// the number, as it is said.
+func expandCurrency(s string) string {
+	return currencyPattern.ReplaceAllStringFunc(s, func(m string) string {
+		g := currencyPattern.FindStringSubmatch(m)
+		names := currencyNames[g[1]]
+		whole, frac := strings.ReplaceAll(g[2], ",", ""), g[3]
+		scale := g[4]
+		if scale == "" {
+			scale = currencyScales[g[5]]
+		}
+
+		if scale != "" {
+			words := spellNumber(whole)
+			if frac != "" {
+				words += " point " + digitWords(frac)
+			}
+			return words + " " + scale + " " + plural(names[0], 2)
+		}
+		n, _ := strconv.ParseUint(whole, 10, 64)
+		words := spellNumber(whole) + " " + plural(names[0], n)
+		switch {
+		case frac == "" || strings.Trim(frac, "0") == "":
+		case len(frac) == 2 && names[1] != "":
+			cents, _ := strconv.ParseUint(frac, 10, 64)
+			words += " and " + cardinal(cents) + " " + plural(names[1], cents)
+			if n == 0 {
+				words = cardinal(cents) + " " + plural(names[1], cents)
+			}
+		default:
+			words = spellNumber(whole+"."+frac) + " " + plural(names[0], 2)
+		}
+		return words
+	})
+}
+
+// plural returns the plural of a currency name unless n is 1.
+func plural(name string, n uint64) string {
+	switch {
+	case n == 1 || name == "yen":
+		return name
+	case name == "penny":
+		return "pence"
+	case name == "paisa":
+		return "paise"
+	}
+	return name + "s"
+}
+
+var months = []string{
+	"January", "February", "March", "April", "May", "June", "July",
+	"August", "September", "October", "November", "December",
+}
+
+// monthName matches a month name or its abbreviation as a whole word,
+// with an optional period.
+const monthName = `(Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|June?|July?|Aug(?:ust)?|Sep(?:t(?:ember)?)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\b\.?`
+
+var (
+	isoDate = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+
+	// monthFirst matches "March 15", "Mar. 15th, 2024" and "March 2024".
+	monthFirst = regexp.MustCompile(`\b` + monthName + `\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4})\b)?`)
+	monthYear  = regexp.MustCompile(`\b(January|February|March|April|May|June|July|August|September|October|November|December)\s+(\d{4})\b`)
+
+	// dayFirst matches "15 March 2024" and "15th of March".
+	dayFirst = regexp.MustCompile(`\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthName + `(?:\s+(\d{4})\b)?`)
+)
+
+// monthIndex returns the month for an English month name or
+// abbreviation, or -1.
+func monthIndex(name string) int {
+	for i, m := range months {
+		if strings.HasPrefix(m, name[:3]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// expandDates spells out dates: ISO dates and dates with a month name
+// become "March fifteenth, twenty twenty-four".
+func expandDates(s string) string {
+	s = isoDate.ReplaceAllStringFunc(s, func(m string) string {
+		g := isoDate.FindStringSubmatch(m)
+		y, _ := strconv.Atoi(g[1])
+		mo, _ := strconv.Atoi(g[2])
+		d, _ := strconv.Atoi(g[3])
+		if mo < 1 || mo > 12 || d < 1 || d > 31 {
+			return m
+		}
+		return months[mo-1] + " " + ordinal(uint64(d)) + ", " + year(y)
+	})
+	s = monthFirst.ReplaceAllStringFunc(s, func(m string) string {
+		g := monthFirst.FindStringSubmatch(m)
+		d, _ := strconv.Atoi(g[2])
+		if d < 1 || d > 31 {
+			return m
+		}
+		out := months[monthIndex(g[1])] + " " + ordinal(uint64(d))
+		if g[3] != "" {
+			y, _ := strconv.Atoi(g[3])
+			out += ", " + year(y)
+		}
+		return out
+	})
+	s = monthYear.ReplaceAllStringFunc(s, func(m string) string {
+		g := monthYear.FindStringSubmatch(m)
+		y, _ := strconv.Atoi(g[2])
+		return g[1] + " " + year(y)
+	})
+	s = dayFirst.ReplaceAllStringFunc(s, func(m string) string {
+		g := dayFirst.FindStringSubmatch(m)
+		d, _ := strconv.Atoi(g[1])
+		if d < 1 || d > 31 {
+			return m
+		}
+		out := "the " + ordinal(uint64(d)) + " of " + months[monthIndex(g[2])]
+		if g[3] != "" {
+			y, _ := strconv.Atoi(g[3])
+			out += " " + year(y)
+		}
+		return out
+	})
+	return s
+}
+
+// clockTime matches times of day such as "9:30" and "10:05 pm".
+var clockTime = regexp.MustCompile(`\b([01]?\d|2[0-3]):([0-5]\d)(?:\s?([AaPp])\.?[Mm]\.?)?\b`)
+
+// expandTimes spells out times of day: "10:05 pm" → "ten oh five PM".
+func expandTimes(s string) string {
+	return clockTime.ReplaceAllStringFunc(s, func(m string) string {
+		g := clockTime.FindStringSubmatch(m)
+		h, _ := strconv.Atoi(g[1])
+		min, _ := strconv.Atoi(g[2])
+		out := cardinal(uint64(h))
+		switch {
+		case min == 0 && g[3] == "":
+			out += " o'clock"
+		case min == 0:
+		case min < 10:
+			out += " oh " + smallNumbers[min]
+		default:
+			out += " " + belowThousand(min)
+		}
+		if g[3] != "" {
+			out += " " + strings.ToUpper(g[3]) + "M"
+		}
+		return out
+	})
+}
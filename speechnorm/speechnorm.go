@@ -0,0 +1,196 @@
+// Package speechnorm rewrites extracted text into the form a speech
+// synthesizer reads well: markdown and citation markers are removed,
+// URLs are shortened to their domain, emoji are dropped, and numbers,
+// currency, dates, symbols and common abbreviations are spelled out.
+// Paragraph breaks are kept.
+package speechnorm
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Normalize returns text in speakable form.
+func Normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := stripMarkdown(strings.Split(text, "\n"))
+	for i, line := range lines {
+		lines[i] = normalizeLine(line)
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// normalizeLine applies the inline rewrites to one line. The order
+// matters: URLs go before anything that would rewrite their digits or
+// dots, and dates and currency before plain numbers.
+func normalizeLine(s string) string {
+	s = shortenURLs(s)
+	s = stripEmoji(s)
+	s = expandDates(s)
+	s = expandTimes(s)
+	s = expandCurrency(s)
+	s = expandSymbols(s)
+	s = expandAbbreviations(s)
+	s = expandNumbers(s)
+
+	s = spaces.ReplaceAllString(s, " ")
+	s = spaceBeforePunct.ReplaceAllString(s, "$1")
+	return strings.TrimSpace(s)
+}
+
+var (
+	blankLines       = regexp.MustCompile(`\n(?:[ \t]*\n)+`)
+	spaces           = regexp.MustCompile(`[ \t\x{00a0}]+`)
+	spaceBeforePunct = regexp.MustCompile(` ([,.;:!?])`)
+
+	// citation matches reference markers such as [1], [2, 3], [4–6],
+	// [a] and [citation needed], with the space before them.
+	citation = regexp.MustCompile(`\s?\[(?:\d+(?:\s*[,–-]\s*\d+)*|[a-z]|note \d+|citation needed|clarification needed)\]`)
+
+	// endnoteMark matches the endnote markers the DOCX and DOC
+	// extractors write, such as [iv]: a lower-case Roman numeral right
+	// after the text it annotates. A bracketed [ii] after a space, as in
+	// a list, is left alone. The numeral may match empty; see
+	// stripEndnoteMarks.
+	endnoteMark = regexp.MustCompile(`[^\s\[(]\[c{0,3}(?:xc|xl|l?x{0,3})(?:ix|iv|v?i{0,3})\]`)
+)
+
+// stripEndnoteMarks removes endnote markers from line, keeping the
+// character before each.
+func stripEndnoteMarks(line string) string {
+	return endnoteMark.ReplaceAllStringFunc(line, func(m string) string {
+		i := strings.LastIndexByte(m, '[')
+		if i == len(m)-2 { // "[]"
+			return m
+		}
+		return m[:i]
+	})
+}
+
+// Markdown syntax. Code blocks are dropped entirely; the rest is
+// reduced to its text.
+var (
+	mdFence     = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	mdHeading   = regexp.MustCompile(`^\s{0,3}#{1,6}\s+(.*?)\s*#*\s*$`)
+	mdQuote     = regexp.MustCompile(`^\s{0,3}(?:>\s?)+`)
+	mdBullet    = regexp.MustCompile(`^\s*[-*+]\s+(?:\[[ xX]\]\s+)?`)
+	mdRule      = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdTableRule = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(?:\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	mdLinkDef   = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+`)
+	mdImage     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink      = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdRefLink   = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+	mdAutolink  = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	mdStrong    = regexp.MustCompile(`(^|[^\w*])\*\*([^*\s](?:[^*]*?[^*\s])?)\*\*([^\w*]|$)`) // not inside 5**2
+	mdEm        = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*?[^*\s])?)\*([^\w*]|$)`)     // not inside 5*3*2
+	mdStrike    = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	mdCode      = regexp.MustCompile("`+([^`]+)`+")
+	mdUnderline = regexp.MustCompile(`(^|[^\w])__?(\S(?:[^_]*?\S)?)__?([^\w]|$)`) // not inside snake_case
+	mdHTMLTag   = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// stripMarkdown removes markdown syntax and citation markers from
+// lines, keeping their text. Lines that are nothing but syntax are
+// dropped, and a code block becomes a paragraph break.
+func stripMarkdown(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	inFence := false
+	for _, line := range lines {
+		if mdFence.MatchString(line) {
+			inFence = !inFence
+			out = append(out, "")
+			continue
+		}
+		if inFence || mdRule.MatchString(line) || mdTableRule.MatchString(line) || mdLinkDef.MatchString(line) {
+			continue
+		}
+		// Before links, so [1][2] isn't read as a reference link.
+		line = citation.ReplaceAllString(line, "")
+		line = stripEndnoteMarks(line)
+		line = mdHeading.ReplaceAllString(line, "$1")
+		line = mdQuote.ReplaceAllString(line, "")
+		line = mdBullet.ReplaceAllString(line, "")
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "|") && strings.HasSuffix(t, "|") {
+			cells := strings.Split(strings.Trim(t, "|"), "|")
+			for i, c := range cells {
+				cells[i] = strings.TrimSpace(c)
+			}
+			line = strings.Join(cells, ", ")
+		}
+		line = mdImage.ReplaceAllString(line, "$1")
+		line = mdLink.ReplaceAllString(line, "$1")
+		line = mdRefLink.ReplaceAllString(line, "$1")
+		line = mdAutolink.ReplaceAllString(line, "$1")
+		// Emphasis first, so emphasis nested in strong text is gone
+		// before strong text, which may not contain '*', is matched.
+		line = replaceDelimited(mdEm, line)
+		line = replaceDelimited(mdStrong, line)
+		line = mdStrike.ReplaceAllString(line, "$1")
+		line = mdCode.ReplaceAllString(line, "$1")
+		line = mdUnderline.ReplaceAllString(line, "$1$2$3")
+		line = mdHTMLTag.ReplaceAllString(line, "")
+		out = append(out, line)
+	}
+	return out
+}
+
+// replaceDelimited strips emphasis matched by re, which captures the
+// characters on either side of the delimiters. Matches cannot share
+// those characters, so "*a* *b*" needs a second pass; each pass removes
+// delimiters, so the loop ends.
+func replaceDelimited(re *regexp.Regexp, line string) string {
+	for {
+		next := re.ReplaceAllString(line, "$1$2$3")
+		if next == line {
+			return line
+		}
+		line = next
+	}
+}
+
+// urlPattern matches web addresses in running text.
+var urlPattern = regexp.MustCompile(`\b(?:https?://|www\.)[^\s<>"'\]]+`)
+
+// shortenURLs replaces each URL with its domain, which is all a listener
+// can use: "https://www.example.com/a/b?c=d" becomes "example.com".
+func shortenURLs(s string) string {
+	return urlPattern.ReplaceAllStringFunc(s, func(link string) string {
+		trimmed := strings.TrimRight(link, ".,;:!?)")
+		trail := link[len(trimmed):]
+		raw := trimmed
+		if strings.HasPrefix(raw, "www.") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return link
+		}
+		return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") + trail
+	})
+}
+
+// stripEmoji removes emoji, along with the joiners, variation selectors
+// and skin-tone modifiers that build them.
+func stripEmoji(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, flags, skin tones
+		r >= 0x2600 && r <= 0x27BF,   // miscellaneous symbols, dingbats
+		r >= 0x2B00 && r <= 0x2BFF,   // stars, arrows and squares
+		r >= 0xE0020 && r <= 0xE007F, // tag sequences
+		r == 0x200D, r == 0xFE0E, r == 0xFE0F, r == 0x20E3:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Command is a Synthesizer that shells out to a local speech engine such
+// as espeak-ng or piper. The text is written to the command's stdin and
+// the audio is read from its stdout.
+//
+// Args may contain these placeholders, substituted per request:
+//
+//	{voice}        the requested voice, or DefaultVoice
+//	{rate}         the rate multiplier, e.g. "1.25"
+//	{wpm}          words per minute, 175 × rate (espeak's -s)
+//	{length_scale} 1 / rate (piper's --length_scale)
+//
+// If no voice is available, an argument containing {voice} is dropped
+// together with the flag before it, so the engine uses its own default.
+type Command struct {
+	Path         string
+	Args         []string
+	DefaultVoice string
+
+	// RawFormat, when set, means the command writes headerless PCM in
+	// this format instead of a WAV file.
+	RawFormat *Format
+}
+
+// ParseCommand splits a command line on whitespace into a Command. No
+// shell is involved, so quoting is not supported.
+func ParseCommand(spec string) (*Command, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty TTS command")
+	}
+	return &Command{Path: fields[0], Args: fields[1:]}, nil
+}
+
+// voicePattern restricts voice names to characters engines use, and
+// stops a request from smuggling in extra command-line flags.
+var voicePattern = regexp.MustCompile(`^[A-Za-z0-9_.+/][A-Za-z0-9_.+/:\-]*$`)
+
+// Synthesize runs the command for req.
+func (c *Command) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	voice := req.Voice
+	if voice == "" {
+		voice = c.DefaultVoice
+	}
+	if voice != "" && !voicePattern.MatchString(voice) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidVoice, voice)
+	}
+	rate := req.Rate
+	if rate <= 0 {
+		rate = 1
+	}
+
+	replacer := strings.NewReplacer(
+		"{voice}", voice,
+		"{rate}", strconv.FormatFloat(rate, 'f', 2, 64),
+		"{wpm}", strconv.Itoa(int(175*rate)),
+		"{length_scale}", strconv.FormatFloat(1/rate, 'f', 3, 64),
+	)
+	args := make([]string, 0, len(c.Args))
+	for _, arg := range c.Args {
+		if voice == "" && strings.Contains(arg, "{voice}") {
+			if n := len(args); n > 0 && strings.HasPrefix(args[n-1], "-") {
+				args = args[:n-1]
+			}
+			continue
+		}
+		args = append(args, replacer.Replace(arg))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Path, args...)
+	cmd.Stdin = strings.NewReader(req.Text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", c.Path, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", c.Path, err)
+	}
+
+	if c.RawFormat != nil {
+		pcm := stdout.Bytes()
+		pcm = pcm[:len(pcm)-len(pcm)%(c.RawFormat.Channels*2)]
+		return &Audio{Format: *c.RawFormat, PCM: pcm}, nil
+	}
+	audio, err := ReadWAV(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("%s output: %w", c.Path, err)
+	}
+	return audio, nil
+}
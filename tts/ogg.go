@@ -0,0 +1,58 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ErrOggUnavailable is returned by WriteOgg when no Ogg Vorbis encoder
+// is installed.
+var ErrOggUnavailable = errors.New("exporting Ogg needs oggenc (vorbis-tools) installed")
+
+// WriteOgg encodes r as Ogg Vorbis by piping it through oggenc. Its
+// markers are written as CHAPTERxxx Vorbis comments, the convention
+// podcast players read for chapter lists.
+func WriteOgg(ctx context.Context, w io.Writer, r *Rendering, title string) error {
+	path, err := exec.LookPath("oggenc")
+	if err != nil {
+		return ErrOggUnavailable
+	}
+
+	wav, _, err := r.WAV()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"--quiet", "--output=-"}
+	if title != "" {
+		args = append(args, "--comment=TITLE="+title)
+	}
+	for i, m := range r.Markers {
+		args = append(args,
+			fmt.Sprintf("--comment=CHAPTER%03d=%s", i+1, chapterTimestamp(m)),
+			fmt.Sprintf("--comment=CHAPTER%03dNAME=%s", i+1, m.Title))
+	}
+	args = append(args, "-")
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = wav
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("oggenc: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// chapterTimestamp formats a marker offset as HH:MM:SS.mmm.
+func chapterTimestamp(m Marker) string {
+	ms := m.Offset.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d",
+		ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
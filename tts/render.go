@@ -0,0 +1,176 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxChunkChars is the most text sent to a Synthesizer in one call when
+// rendering a long document. Engines slow down or fail on very long
+// inputs, and smaller chunks let cancellation take effect sooner.
+const maxChunkChars = 2000
+
+// Chapter is a titled run of text to render. An empty Title renders the
+// text without adding a marker.
+type Chapter struct {
+	Title string
+	Text  string
+}
+
+// Marker labels a point in rendered audio, such as a chapter start.
+type Marker struct {
+	Title  string
+	Offset time.Duration
+	Frame  int // sample frame the marker points at
+}
+
+// ErrTooLong is returned by Render when the audio would run past
+// maxRenderDuration or outgrow a WAV file.
+var ErrTooLong = errors.New("rendered audio is too long")
+
+// maxRenderDuration caps a render. Audio is kept on disk, not in
+// memory, but a day of speech is still no use to anyone.
+const maxRenderDuration = 8 * time.Hour
+
+// Rendering is audio rendered to a temporary file, with its markers.
+// Close removes the file.
+type Rendering struct {
+	Format  Format
+	Markers []Marker
+
+	file *os.File
+	size int64 // bytes of PCM in file
+}
+
+// Duration returns the playing time of the audio.
+func (r *Rendering) Duration() time.Duration {
+	return pcmDuration(r.Format, r.size)
+}
+
+// WAV returns a reader of the audio as a RIFF/WAVE file with the
+// markers as cue points (see WriteWAVMarkers), and the file's size.
+func (r *Rendering) WAV() (io.Reader, int64, error) {
+	return wavReader(r.Format, io.NewSectionReader(r.file, 0, r.size), r.size, r.Markers)
+}
+
+// Close removes the rendered audio.
+func (r *Rendering) Close() error {
+	err := r.file.Close()
+	os.Remove(r.file.Name())
+	return err
+}
+
+// Render synthesizes chapters in order, splitting long text into chunks
+// and concatenating the results in a temporary file. The rendering has
+// a marker at the start of every titled chapter. All chunks must come
+// back in the same format. The caller must Close the rendering.
+func Render(ctx context.Context, s Synthesizer, chapters []Chapter, voice string, rate float64) (*Rendering, error) {
+	f, err := os.CreateTemp("", "read-aloud-render-*.pcm")
+	if err != nil {
+		return nil, err
+	}
+	out := &Rendering{file: f}
+	if err := out.render(ctx, s, chapters, voice, rate); err != nil {
+		out.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Rendering) render(ctx context.Context, s Synthesizer, chapters []Chapter, voice string, rate float64) error {
+	started := false
+	for _, ch := range chapters {
+		pending := ch.Title != ""
+
+		for _, chunk := range splitChunks(ch.Text, maxChunkChars) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			audio, err := s.Synthesize(ctx, Request{Text: chunk, Voice: voice, Rate: rate})
+			if err != nil {
+				return err
+			}
+			if !started {
+				r.Format, started = audio.Format, true
+			} else if audio.Format != r.Format {
+				return fmt.Errorf("synthesizer changed audio format mid-render (%+v, then %+v)",
+					r.Format, audio.Format)
+			}
+			// Record the marker once we know the format, so the offset
+			// can be computed.
+			if pending {
+				frame := int(r.size) / (r.Format.Channels * 2)
+				r.Markers = append(r.Markers, Marker{
+					Title:  ch.Title,
+					Frame:  frame,
+					Offset: time.Duration(frame) * time.Second / time.Duration(r.Format.SampleRate),
+				})
+				pending = false
+			}
+			size := r.size + int64(len(audio.PCM))
+			if size > maxWAVData || pcmDuration(r.Format, size) > maxRenderDuration {
+				return ErrTooLong
+			}
+			if _, err := r.file.Write(audio.PCM); err != nil {
+				return err
+			}
+			r.size = size
+		}
+	}
+
+	if !started {
+		return fmt.Errorf("nothing to render")
+	}
+	return nil
+}
+
+// splitChunks breaks text into pieces of at most max characters,
+// preferring paragraph breaks, then sentence ends, then spaces.
+func splitChunks(text string, max int) []string {
+	var chunks []string
+	for _, para := range strings.Split(text, "\n") {
+		para = strings.TrimSpace(para)
+		for len([]rune(para)) > max {
+			runes := []rune(para)
+			cut := lastBreak(runes[:max])
+			chunks = append(chunks, strings.TrimSpace(string(runes[:cut])))
+			para = strings.TrimSpace(string(runes[cut:]))
+		}
+		if para == "" {
+			continue
+		}
+		// Merge short paragraphs so the engine isn't called per line.
+		if n := len(chunks); n > 0 && len([]rune(chunks[n-1]))+1+len([]rune(para)) <= max {
+			chunks[n-1] += "\n" + para
+		} else {
+			chunks = append(chunks, para)
+		}
+	}
+	return chunks
+}
+
+// lastBreak returns the index just past the last sentence end in runes,
+// or past the last space, or len(runes) if there is neither.
+func lastBreak(runes []rune) int {
+	space := -1
+	for i := len(runes) - 1; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			if strings.ContainsRune(".!?;:", runes[i-1]) {
+				return i
+			}
+			if space < 0 {
+				space = i
+			}
+		}
+	}
+	if space > 0 {
+		return space
+	}
+	return len(runes)
+}
@@ -0,0 +1,155 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingSynth wraps Tone and records the text of every request.
+type recordingSynth struct {
+	Tone
+	texts []string
+}
+
+func (s *recordingSynth) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	s.texts = append(s.texts, req.Text)
+	return s.Tone.Synthesize(ctx, req)
+}
+
+func TestRenderChunksLongText(t *testing.T) {
+	sentence := "This sentence is long enough to need several of its kind. "
+	long := strings.Repeat(sentence, 3*maxChunkChars/len(sentence))
+
+	synth := &recordingSynth{}
+	r, err := Render(context.Background(), synth, []Chapter{{Text: long}}, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if len(synth.texts) < 3 {
+		t.Fatalf("rendered in %d chunks, want at least 3", len(synth.texts))
+	}
+	for i, text := range synth.texts {
+		if n := len([]rune(text)); n > maxChunkChars {
+			t.Errorf("chunk %d has %d characters, want at most %d", i, n, maxChunkChars)
+		}
+		if i < len(synth.texts)-1 && !strings.HasSuffix(text, ".") {
+			t.Errorf("chunk %d does not end at a sentence: %q", i, text[len(text)-20:])
+		}
+	}
+	if got, want := strings.Join(synth.texts, " "), strings.TrimSpace(long); got != want {
+		t.Error("chunks do not add up to the text")
+	}
+}
+
+func TestRenderMarkers(t *testing.T) {
+	chapters := []Chapter{
+		{Title: "One", Text: "a bb ccc"},
+		{Text: "untitled text"},
+		{Title: "Two", Text: "dddd"},
+	}
+	r, err := Render(context.Background(), &Tone{}, chapters, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// Tone renders each word as 0.2s at normal rate: 3 words, then 2.
+	want := []Marker{
+		{Title: "One", Frame: 0, Offset: 0},
+		{Title: "Two", Frame: 5 * 3200, Offset: time.Second},
+	}
+	if len(r.Markers) != len(want) {
+		t.Fatalf("markers = %+v, want %+v", r.Markers, want)
+	}
+	for i := range want {
+		if r.Markers[i] != want[i] {
+			t.Errorf("marker %d = %+v, want %+v", i, r.Markers[i], want[i])
+		}
+	}
+	if got := r.Duration(); got != 1200*time.Millisecond {
+		t.Errorf("duration = %v, want 1.2s", got)
+	}
+}
+
+func TestRenderWAV(t *testing.T) {
+	r, err := Render(context.Background(), &Tone{}, []Chapter{{Title: "Intro", Text: "hello there"}}, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	wav, size, err := r.WAV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(data)) != size {
+		t.Errorf("WAV is %d bytes, reported size %d", len(data), size)
+	}
+	audio, err := ReadWAV(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audio.Duration() != r.Duration() {
+		t.Errorf("WAV duration = %v, want %v", audio.Duration(), r.Duration())
+	}
+}
+
+// formatSwitcher returns audio at a different sample rate every call.
+type formatSwitcher struct{ calls int }
+
+func (s *formatSwitcher) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	s.calls++
+	return (&Tone{SampleRate: 8000 * s.calls}).Synthesize(ctx, req)
+}
+
+func TestRenderFormatChange(t *testing.T) {
+	chapters := []Chapter{{Title: "A", Text: "one"}, {Title: "B", Text: "two"}}
+	if _, err := Render(context.Background(), &formatSwitcher{}, chapters, "", 1); err == nil {
+		t.Error("Render succeeded although the format changed")
+	}
+}
+
+func TestRenderCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Render(ctx, &Tone{}, []Chapter{{Text: "words"}}, "", 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRenderNothing(t *testing.T) {
+	if _, err := Render(context.Background(), &Tone{}, []Chapter{{Title: "Empty"}}, "", 1); err == nil {
+		t.Error("Render of no text succeeded")
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"one\ntwo\n\nthree", 10, []string{"one\ntwo", "three"}},
+		{"First one. Second one.", 15, []string{"First one.", "Second one."}},
+		{"no sentence ends here", 10, []string{"no", "sentence", "ends here"}},
+		{"abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+	}
+	for _, tt := range tests {
+		got := splitChunks(tt.text, tt.max)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("splitChunks(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tts
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+)
+
+// Tone is a deterministic Synthesizer for tests and development. Each
+// word becomes a short sine beep whose pitch depends on the word's
+// length, followed by a gap, so the same request always renders to the
+// same bytes and longer text renders to longer audio.
+type Tone struct {
+	SampleRate int // defaults to 16000
+}
+
+// Synthesize renders req as a sequence of beeps.
+func (t *Tone) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	rate := t.SampleRate
+	if rate <= 0 {
+		rate = 16000
+	}
+	speed := req.Rate
+	if speed <= 0 {
+		speed = 1
+	}
+
+	beep := int(float64(rate) * 0.15 / speed)
+	gap := int(float64(rate) * 0.05 / speed)
+
+	words := strings.Fields(req.Text)
+	pcm := make([]byte, 0, len(words)*(beep+gap)*2)
+	for i, word := range words {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		freq := 220 + 40*float64(len([]rune(word))%12)
+		for n := 0; n < beep; n++ {
+			v := 0.3 * math.Sin(2*math.Pi*freq*float64(n)/float64(rate))
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(int16(v*math.MaxInt16)))
+		}
+		pcm = append(pcm, make([]byte, gap*2)...)
+	}
+
+	return &Audio{Format: Format{SampleRate: rate, Channels: 1}, PCM: pcm}, nil
+}
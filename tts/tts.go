@@ -0,0 +1,103 @@
+// Package tts renders text to speech on the server. Backends implement
+// Synthesizer and return 16-bit PCM audio that can be written out as WAV
+// or concatenated with other renders of the same format.
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ErrNoSynthesizer is returned when no speech backend is configured.
+var ErrNoSynthesizer = errors.New("no speech synthesizer configured")
+
+// ErrInvalidVoice is returned when a request names a voice the backend
+// cannot accept.
+var ErrInvalidVoice = errors.New("invalid voice name")
+
+// Request describes a piece of text to render.
+type Request struct {
+	Text  string
+	Voice string  // backend-specific voice name; empty for the default
+	Rate  float64 // speaking rate multiplier; 1.0 is normal speed
+}
+
+// Format describes signed 16-bit little-endian PCM audio.
+type Format struct {
+	SampleRate int
+	Channels   int
+}
+
+// Audio is a block of signed 16-bit little-endian PCM samples.
+type Audio struct {
+	Format Format
+	PCM    []byte
+}
+
+// Duration returns the playing time of the audio.
+func (a *Audio) Duration() time.Duration {
+	return pcmDuration(a.Format, int64(len(a.PCM)))
+}
+
+// pcmDuration returns the playing time of n bytes of PCM in format f.
+func pcmDuration(f Format, n int64) time.Duration {
+	bytesPerSecond := int64(f.SampleRate * f.Channels * 2)
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second / time.Duration(bytesPerSecond)
+}
+
+// Synthesizer renders text to audio.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, req Request) (*Audio, error)
+}
+
+// FromEnv returns the synthesizer configured by the environment:
+//
+//   - READ_ALOUD_TTS=tone selects the deterministic test tone backend.
+//   - READ_ALOUD_TTS_COMMAND sets a command line for the Command backend,
+//     e.g. "espeak-ng --stdout -v {voice} -s {wpm}".
+//   - READ_ALOUD_TTS_VOICE sets the default voice for that command.
+//   - READ_ALOUD_TTS_SAMPLE_RATE, when set, means the command writes raw
+//     mono PCM at that rate (e.g. piper --output-raw) instead of WAV.
+//
+// Without configuration it falls back to espeak-ng or espeak if one is
+// on the PATH, and returns ErrNoSynthesizer otherwise.
+func FromEnv() (Synthesizer, error) {
+	if os.Getenv("READ_ALOUD_TTS") == "tone" {
+		return &Tone{}, nil
+	}
+
+	spec := os.Getenv("READ_ALOUD_TTS_COMMAND")
+	if spec == "" {
+		for _, name := range []string{"espeak-ng", "espeak"} {
+			if _, err := exec.LookPath(name); err == nil {
+				spec = name + " --stdout -v {voice} -s {wpm}"
+				break
+			}
+		}
+	}
+	if spec == "" {
+		return nil, ErrNoSynthesizer
+	}
+
+	cmd, err := ParseCommand(spec)
+	if err != nil {
+		return nil, err
+	}
+	cmd.DefaultVoice = os.Getenv("READ_ALOUD_TTS_VOICE")
+	if v := os.Getenv("READ_ALOUD_TTS_SAMPLE_RATE"); v != "" {
+		rate, err := strconv.Atoi(v)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid READ_ALOUD_TTS_SAMPLE_RATE %q", v)
+		}
+		cmd.RawFormat = &Format{SampleRate: rate, Channels: 1}
+	}
+	return cmd, nil
+}
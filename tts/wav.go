@@ -0,0 +1,163 @@
+package tts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WriteWAV writes a as a RIFF/WAVE file.
+func WriteWAV(w io.Writer, a *Audio) error {
+	return WriteWAVMarkers(w, a, nil)
+}
+
+// WriteWAVMarkers writes a as a RIFF/WAVE file with markers stored as
+// cue points labelled in a LIST/adtl chunk, which audio editors and
+// some players show as chapters.
+func WriteWAVMarkers(w io.Writer, a *Audio, markers []Marker) error {
+	r, _, err := wavReader(a.Format, bytes.NewReader(a.PCM), int64(len(a.PCM)), markers)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// maxWAVData is the most PCM a WAV file can hold: the RIFF size is a
+// uint32, and has to cover the headers and cue chunks too.
+const maxWAVData = math.MaxUint32 - 1<<20
+
+// wavReader returns a reader of a WAV file holding the n bytes of PCM
+// read from pcm, and the file's size. Audio too long for the format's
+// 32-bit sizes is an error rather than a file with wrapped sizes.
+func wavReader(f Format, pcm io.Reader, n int64, markers []Marker) (io.Reader, int64, error) {
+	trailer := wavCueChunks(markers)
+	var header bytes.Buffer
+	if err := writeWAVHeader(&header, f, n, int64(len(trailer))); err != nil {
+		return nil, 0, err
+	}
+	pad := make([]byte, n%2)
+	size := int64(header.Len()) + n + int64(len(pad)) + int64(len(trailer))
+	return io.MultiReader(&header, io.LimitReader(pcm, n), bytes.NewReader(pad), bytes.NewReader(trailer)), size, nil
+}
+
+// wavCueChunks encodes markers as a "cue " chunk and a LIST/adtl chunk
+// of "labl" entries. It returns nil when there are no markers.
+func wavCueChunks(markers []Marker) []byte {
+	if len(markers) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("cue ")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+24*len(markers)))
+	binary.Write(&buf, binary.LittleEndian, uint32(len(markers)))
+	for i, m := range markers {
+		binary.Write(&buf, binary.LittleEndian, uint32(i+1)) // cue point ID
+		binary.Write(&buf, binary.LittleEndian, uint32(m.Frame))
+		buf.WriteString("data")
+		binary.Write(&buf, binary.LittleEndian, uint32(0)) // chunk start
+		binary.Write(&buf, binary.LittleEndian, uint32(0)) // block start
+		binary.Write(&buf, binary.LittleEndian, uint32(m.Frame))
+	}
+
+	var labels bytes.Buffer
+	labels.WriteString("adtl")
+	for i, m := range markers {
+		text := append([]byte(m.Title), 0)
+		labels.WriteString("labl")
+		binary.Write(&labels, binary.LittleEndian, uint32(4+len(text)))
+		binary.Write(&labels, binary.LittleEndian, uint32(i+1))
+		labels.Write(text)
+		if len(text)%2 == 1 {
+			labels.WriteByte(0)
+		}
+	}
+	buf.WriteString("LIST")
+	binary.Write(&buf, binary.LittleEndian, uint32(labels.Len()))
+	buf.Write(labels.Bytes())
+
+	return buf.Bytes()
+}
+
+// writeWAVHeader writes the RIFF header, fmt chunk and data chunk header
+// for dataLen bytes of PCM. extra is the size of any chunks that will
+// follow the data, so the RIFF size stays correct.
+func writeWAVHeader(buf *bytes.Buffer, f Format, dataLen, extra int64) error {
+	riffSize := 4 + 8 + 16 + 8 + dataLen + dataLen%2 + extra
+	if riffSize > math.MaxUint32 {
+		return ErrTooLong
+	}
+	blockAlign := f.Channels * 2
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(riffSize))
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(buf, binary.LittleEndian, uint16(f.Channels))
+	binary.Write(buf, binary.LittleEndian, uint32(f.SampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(f.SampleRate*blockAlign))
+	binary.Write(buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(buf, binary.LittleEndian, uint16(16))
+
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(dataLen))
+	return nil
+}
+
+// ReadWAV parses a 16-bit PCM WAV file. Tools that stream WAV to a pipe
+// (espeak-ng --stdout) cannot seek back to fill in chunk sizes, so a
+// data chunk that claims to run past the end of the input is read to
+// EOF instead, as is one whose size was left at zero.
+func ReadWAV(r io.Reader) (*Audio, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a WAV file")
+	}
+
+	var format *Format
+	pos := 12
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size < 0 || size > len(body) || (id == "data" && size == 0) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return nil, fmt.Errorf("short fmt chunk")
+			}
+			codec := binary.LittleEndian.Uint16(body[0:2])
+			bits := binary.LittleEndian.Uint16(body[14:16])
+			if codec != 1 || bits != 16 {
+				return nil, fmt.Errorf("unsupported WAV encoding (format %d, %d bits)", codec, bits)
+			}
+			format = &Format{
+				Channels:   int(binary.LittleEndian.Uint16(body[2:4])),
+				SampleRate: int(binary.LittleEndian.Uint32(body[4:8])),
+			}
+			if format.Channels < 1 || format.SampleRate < 1 {
+				return nil, fmt.Errorf("invalid WAV format")
+			}
+		case "data":
+			if format == nil {
+				return nil, fmt.Errorf("WAV data before fmt chunk")
+			}
+			pcm := body[:len(body)-len(body)%(format.Channels*2)]
+			return &Audio{Format: *format, PCM: pcm}, nil
+		}
+		pos += 8 + size + size%2
+	}
+	return nil, fmt.Errorf("WAV file has no data chunk")
+}
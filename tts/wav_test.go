@@ -0,0 +1,142 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWriteWAVHeader(t *testing.T) {
+	audio, err := (&Tone{SampleRate: 22050}).Synthesize(context.Background(), Request{Text: "one two"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, audio); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	if string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" || string(b[12:16]) != "fmt " {
+		t.Fatalf("bad WAV header %q", b[:16])
+	}
+	u16 := func(off int) int { return int(binary.LittleEndian.Uint16(b[off:])) }
+	u32 := func(off int) int { return int(binary.LittleEndian.Uint32(b[off:])) }
+	checks := []struct {
+		name      string
+		got, want int
+	}{
+		{"RIFF size", u32(4), len(b) - 8},
+		{"fmt size", u32(16), 16},
+		{"codec", u16(20), 1},
+		{"channels", u16(22), 1},
+		{"sample rate", u32(24), 22050},
+		{"byte rate", u32(28), 22050 * 2},
+		{"block align", u16(32), 2},
+		{"bits per sample", u16(34), 16},
+		{"data size", u32(40), len(audio.PCM)},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+	if string(b[36:40]) != "data" || !bytes.Equal(b[44:], audio.PCM) {
+		t.Error("data chunk does not hold the PCM")
+	}
+}
+
+func TestWriteWAVMarkers(t *testing.T) {
+	audio := &Audio{Format: Format{SampleRate: 8000, Channels: 1}, PCM: make([]byte, 801)}
+	markers := []Marker{{Title: "Start", Frame: 0}, {Title: "Middle", Frame: 200}}
+	var buf bytes.Buffer
+	if err := WriteWAVMarkers(&buf, audio, markers); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	if got := int(binary.LittleEndian.Uint32(b[4:])); got != len(b)-8 {
+		t.Errorf("RIFF size = %d, want %d", got, len(b)-8)
+	}
+
+	// The odd-sized data chunk is padded, then followed by the cues.
+	cue := 44 + len(audio.PCM) + 1
+	if string(b[cue:cue+4]) != "cue " {
+		t.Fatalf("no cue chunk after data: %q", b[cue:cue+4])
+	}
+	if n := binary.LittleEndian.Uint32(b[cue+8:]); n != 2 {
+		t.Fatalf("cue count = %d, want 2", n)
+	}
+	for i, m := range markers {
+		point := b[cue+12+24*i:]
+		if id := binary.LittleEndian.Uint32(point); id != uint32(i+1) {
+			t.Errorf("cue %d ID = %d", i, id)
+		}
+		if pos := binary.LittleEndian.Uint32(point[4:]); pos != uint32(m.Frame) {
+			t.Errorf("cue %d position = %d, want %d", i, pos, m.Frame)
+		}
+		if string(point[8:12]) != "data" {
+			t.Errorf("cue %d chunk = %q, want data", i, point[8:12])
+		}
+		if off := binary.LittleEndian.Uint32(point[20:]); off != uint32(m.Frame) {
+			t.Errorf("cue %d sample offset = %d, want %d", i, off, m.Frame)
+		}
+	}
+
+	list := b[cue+8+4+24*len(markers):]
+	if string(list[0:4]) != "LIST" || string(list[8:12]) != "adtl" {
+		t.Fatalf("no LIST/adtl chunk after cues: %q", list[:12])
+	}
+	if n := bytes.Count(list, []byte("labl")); n != 2 {
+		t.Errorf("%d labl entries, want 2", n)
+	}
+	for _, title := range []string{"Start\x00", "Middle\x00"} {
+		if !bytes.Contains(list, []byte(title)) {
+			t.Errorf("no label %q", title)
+		}
+	}
+
+	// Readers skip the cue chunks.
+	got, err := ReadWAV(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.PCM) != 800 {
+		t.Errorf("read %d bytes of PCM, want 800", len(got.PCM))
+	}
+}
+
+func TestWAVHeaderTooLong(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeWAVHeader(&buf, Format{SampleRate: 48000, Channels: 2}, math.MaxUint32, 0)
+	if !errors.Is(err, ErrTooLong) {
+		t.Errorf("err = %v, want ErrTooLong", err)
+	}
+}
+
+func TestReadWAVStreamed(t *testing.T) {
+	// espeak-ng --stdout leaves the data size at zero.
+	audio := &Audio{Format: Format{SampleRate: 16000, Channels: 1}, PCM: []byte{1, 2, 3, 4}}
+	var buf bytes.Buffer
+	WriteWAV(&buf, audio)
+	b := buf.Bytes()
+	binary.LittleEndian.PutUint32(b[40:], 0)
+	got, err := ReadWAV(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.PCM, audio.PCM) {
+		t.Errorf("PCM = %v, want %v", got.PCM, audio.PCM)
+	}
+}
+
+func TestToneDeterministic(t *testing.T) {
+	req := Request{Text: "the same words", Rate: 1.5}
+	a, _ := (&Tone{}).Synthesize(context.Background(), req)
+	b, _ := (&Tone{}).Synthesize(context.Background(), req)
+	if !bytes.Equal(a.PCM, b.PCM) || len(a.PCM) == 0 {
+		t.Error("Tone rendered the same request differently")
+	}
+}
@@ -38,6 +38,7 @@
 
   // ========== State ==========
   let currentText = "";
+  let currentLang = ""; // BCP-47 language of currentText, when known
   let currentTitle = "";
   let currentSource = "";
   let currentItemId = null;
@@ -89,7 +90,14 @@
     speedLabel.textContent = parseFloat(speedSlider.value).toFixed(1) + "x";
   });
 
-  // ========== History (localStorage) ==========
+  // ========== History (localStorage + shared server library) ==========
+  // localStorage is always the local cache. When the Go backend is
+  // running, items are also kept in its library (/api/library) so every
+  // device connected to the same server shares one history.
+  let serverLibrary = false;
+  const PROGRESS_SYNC_MS = 5000;
+  const progressSyncedAt = {};
+
   function getHistory() {
     try { return JSON.parse(localStorage.getItem(HISTORY_KEY)) || []; }
     catch { return []; }
@@ -99,27 +107,90 @@
     localStorage.setItem(HISTORY_KEY, JSON.stringify(items.slice(0, MAX_HISTORY)));
   }
 
-  function addToHistory(title, source, text, type) {
+  function libraryRequest(method, path, body) {
+    const opts = { method };
+    if (body !== undefined) {
+      opts.headers = { "Content-Type": "application/json" };
+      opts.body = JSON.stringify(body);
+    }
+    return fetch("/api/library" + path, opts).catch(() => null);
+  }
+
+  /**
+   * Merge the server library into the local cache. Items saved locally
+   * before the server was reachable are uploaded; items that were
+   * synced before but are gone from the server were deleted elsewhere.
+   */
+  async function syncLibrary() {
+    let resp;
+    try { resp = await fetch("/api/library"); } catch { return; }
+    if (!resp.ok) return;
+    const remote = await resp.json();
+    serverLibrary = true;
+
+    const remoteIds = new Set(remote.map((it) => it.id));
+    const merged = remote.map((it) => Object.assign(it, { synced: true }));
+    for (const it of getHistory()) {
+      if (remoteIds.has(it.id) || it.synced) continue;
+      const created = await libraryRequest("POST", "", it);
+      if (created && created.ok) merged.push(Object.assign(await created.json(), { synced: true }));
+    }
+    merged.sort((a, b) => (b.ts || 0) - (a.ts || 0));
+    saveHistory(merged);
+    renderHistory();
+  }
+
+  function addToHistory(title, source, text, type, meta) {
     const items = getHistory();
     const id = Date.now().toString(36) + Math.random().toString(36).slice(2, 6);
     const filtered = items.filter((it) => it.title !== title);
-    filtered.unshift({ id, title, source, text, type, progress: 0, ts: Date.now() });
+    const item = { id, title, source, text, type, progress: 0, ts: Date.now() };
+    if (meta && meta.byline) item.byline = meta.byline;
+    if (meta && meta.published) item.published = Date.parse(meta.published) || undefined;
+    if (meta && meta.language && meta.language !== "und") item.language = meta.language;
+    filtered.unshift(item);
     saveHistory(filtered);
+
+    if (serverLibrary) {
+      items.filter((it) => it.title === title)
+        .forEach((it) => libraryRequest("DELETE", "/" + encodeURIComponent(it.id)));
+      libraryRequest("POST", "", item).then((resp) => {
+        if (resp && resp.ok) markSynced(id);
+      });
+    }
     return id;
   }
 
-  function updateHistoryProgress(id, progress) {
+  function markSynced(id) {
+    const items = getHistory();
+    const item = items.find((it) => it.id === id);
+    if (item) {
+      item.synced = true;
+      saveHistory(items);
+    }
+  }
+
+  function updateHistoryProgress(id, progress, force) {
     const items = getHistory();
     const item = items.find((it) => it.id === id);
     if (item) {
       item.progress = Math.min(100, Math.round(progress));
       saveHistory(items);
+
+      // Throttle server writes; the timer calls this twice a second.
+      const now = Date.now();
+      if (serverLibrary && (force || now - (progressSyncedAt[id] || 0) >= PROGRESS_SYNC_MS)) {
+        progressSyncedAt[id] = now;
+        libraryRequest("PUT", "/" + encodeURIComponent(id) + "/progress",
+          { progress: item.progress });
+      }
     }
   }
 
   function removeFromHistory(id) {
     const items = getHistory().filter((it) => it.id !== id);
     saveHistory(items);
+    if (serverLibrary) libraryRequest("DELETE", "/" + encodeURIComponent(id));
     renderHistory();
   }
 
@@ -147,13 +218,13 @@
           (iconSymbols[type] || "&#128221;") +
         "</div>" +
         '<div class="history-card-title">' + escapeHTML(item.title || "Untitled") + "</div>" +
-        '<div class="history-card-desc">' + escapeHTML((item.source || item.text || "").slice(0, 80)) + "</div>" +
+        '<div class="history-card-desc">' + escapeHTML((sourceLine(item) || item.text || "").slice(0, 80)) + "</div>" +
         '<div class="history-card-progress"><div class="history-card-progress-fill" style="width:' + (item.progress || 0) + '%"></div></div>' +
         '<button class="history-card-delete" title="Remove">&times;</button>';
 
       card.addEventListener("click", (e) => {
         if (e.target.closest(".history-card-delete")) return;
-        openPlayer(item.title, item.source || "", item.text, item.id);
+        openPlayer(item.title, sourceLine(item), item.text, item.id, item.language);
       });
 
       card.querySelector(".history-card-delete").addEventListener("click", (e) => {
@@ -165,6 +236,14 @@
     });
   }
   renderHistory();
+  syncLibrary();
+
+  // Pick up progress made on other devices when coming back to the tab.
+  document.addEventListener("visibilitychange", () => {
+    if (document.visibilityState === "visible" && viewHome.classList.contains("active")) {
+      syncLibrary();
+    }
+  });
 
   // ========== Views ==========
   function showView(view) {
@@ -173,10 +252,11 @@
     view.classList.add("active");
   }
 
-  function openPlayer(title, source, text, historyId) {
+  function openPlayer(title, source, text, historyId, lang) {
     currentTitle = title || "Untitled";
     currentSource = source || "";
     currentText = text;
+    currentLang = lang || "";
     currentItemId = historyId || null;
 
     playerTitle.textContent = currentTitle;
@@ -198,6 +278,7 @@
     stopSpeech();
     renderHistory();
     showView(viewHome);
+    syncLibrary();
   });
 
   // ========== File input ==========
@@ -322,11 +403,8 @@
       showStatus("Extracting article…", "loading");
       type = "url";
     } else if (containsURL(text)) {
-      if (countURLs(text) > 1) {
-        showStatus("We found more than one link. Please paste one URL per submission so we can extract the right article.", "error", true);
-        return;
-      }
-      showStatus("Extracting content from link…", "loading");
+      const n = countURLs(text);
+      showStatus(n > 1 ? "Extracting " + n + " articles…" : "Extracting content from link…", "loading");
       type = "text_with_url";
     } else {
       const id = addToHistory(text.slice(0, 50) + (text.length > 50 ? "…" : ""), "", text, "text");
@@ -357,8 +435,9 @@
         histType = data.warning ? "text" : "url";
       }
 
-      const id = addToHistory(title, source, data.text, histType);
-      openPlayer(title, source, data.text, id);
+      const id = addToHistory(title, source, data.text, histType, data);
+      const item = getHistory().find((it) => it.id === id);
+      openPlayer(title, sourceLine(item), data.text, id, item && item.language);
 
       inputBox.value = "";
       fileInput.value = "";
@@ -385,31 +464,79 @@
     const utterance = new SpeechSynthesisUtterance(currentText);
     utterance.rate = parseFloat(speedSlider.value);
 
-    // Pick selected voice
+    // Pick the selected voice, unless it speaks another language than
+    // the text and a voice for the text's language exists.
     const voices = speechSynthesis.getVoices();
     const selectedURI = voiceSelect.value;
-    if (selectedURI) {
-      const voice = voices.find((v) => v.voiceURI === selectedURI);
-      if (voice) utterance.voice = voice;
+    let voice = selectedURI ? voices.find((v) => v.voiceURI === selectedURI) : null;
+    if (currentLang) {
+      utterance.lang = currentLang;
+      if (!voice || !sameLanguage(voice.lang, currentLang)) {
+        voice = voiceForLanguage(voices, currentLang) || voice;
+      }
     }
+    if (voice) utterance.voice = voice;
 
     speaking = true;
     speechStartTime = Date.now();
     updatePlayerUI();
     startTimer();
 
-    utterance.onend = () => stopSpeech();
+    const finishedId = currentItemId;
+    utterance.onend = () => { stopSpeech(); autoAdvance(finishedId); };
     utterance.onerror = () => stopSpeech();
 
     speechSynthesis.speak(utterance);
   }
 
+  /** Reports whether two BCP-47 tags share a primary language. */
+  function sameLanguage(a, b) {
+    const primary = (tag) => (tag || "").toLowerCase().split(/[-_]/)[0];
+    return primary(a) === primary(b);
+  }
+
+  /**
+   * Returns the best voice for a BCP-47 language: one for the exact tag
+   * ("en-GB"), else any for the same language, preferring local voices.
+   */
+  function voiceForLanguage(voices, lang) {
+    const norm = (tag) => (tag || "").toLowerCase().replace("_", "-");
+    const matches = voices.filter((v) => sameLanguage(v.lang, lang));
+    matches.sort((a, b) => (b.localService ? 1 : 0) - (a.localService ? 1 : 0));
+    return matches.find((v) => norm(v.lang) === norm(lang)) || matches[0] || null;
+  }
+
+  /**
+   * When an article finishes, take it off the server's reading queue and
+   * start whatever is queued next.
+   */
+  async function autoAdvance(finishedId) {
+    if (!serverLibrary || !finishedId) return;
+    let resp;
+    try {
+      resp = await fetch("/api/queue/advance", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ finished: finishedId }),
+      });
+    } catch { return; }
+    if (resp.status !== 200) return;
+    const next = await resp.json();
+    openPlayer(next.title, sourceLine(next), next.text, next.id, next.language);
+    playSpeech();
+  }
+
   // ========== Playback controls ==========
   function stopSpeech() {
     if (typeof speechSynthesis !== "undefined") {
       speechSynthesis.cancel();
     }
 
+    // Record where we stopped so other devices see it right away.
+    if (speaking && currentItemId) {
+      updateHistoryProgress(currentItemId, progressBar.value, true);
+    }
+
     speaking = false;
     clearInterval(speechTimer);
     speechStartTime = 0;
@@ -453,6 +580,19 @@
   btnStop.addEventListener("click", stopSpeech);
 
   // ========== Helpers ==========
+  /** Source, author and date of a history item, e.g. "example.com · Jane Doe · 3 Mar 2024". */
+  function sourceLine(item) {
+    if (!item) return "";
+    const parts = [];
+    if (item.source) parts.push(item.source);
+    if (item.byline) parts.push(item.byline);
+    if (item.published) {
+      parts.push(new Date(item.published).toLocaleDateString(undefined,
+        { year: "numeric", month: "short", day: "numeric" }));
+    }
+    return parts.join(" · ");
+  }
+
   function formatTime(seconds) {
     const m = Math.floor(seconds / 60);
     const s = Math.floor(seconds % 60);
@@ -167,7 +167,7 @@ const ClientExtractor = (function () {
         return extractDOCX(file);
       case "doc":
         throw new Error(
-          ".doc files are not supported. Please convert to .docx, .pdf, or .txt."
+          ".doc files can only be read by the Read Aloud server. Please start it, or convert to .docx, .pdf, or .txt."
         );
       case "txt":
       case "md":